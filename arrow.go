@@ -0,0 +1,187 @@
+package redac
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/apache/arrow/go/v17/arrow"
+	"github.com/apache/arrow/go/v17/arrow/array"
+	"github.com/apache/arrow/go/v17/arrow/ipc"
+	"github.com/apache/arrow/go/v17/arrow/memory"
+	"github.com/apache/arrow/go/v17/parquet"
+	"github.com/apache/arrow/go/v17/parquet/compress"
+	"github.com/apache/arrow/go/v17/parquet/pqarrow"
+)
+
+// ParquetRenderer renders a result as a parquet file.
+type ParquetRenderer struct {
+	rendererBase
+}
+
+func (r *ParquetRenderer) Render(w io.Writer, result *Result) error {
+	record := r.buildRecord(result)
+	defer record.Release()
+
+	props := parquet.NewWriterProperties(parquet.WithCompression(compress.Codecs.Snappy))
+	fw, err := pqarrow.NewFileWriter(record.Schema(), w, props, pqarrow.DefaultWriterProps())
+	if err != nil {
+		return fmt.Errorf("failed to create parquet writer: %w", err)
+	}
+	if err := fw.Write(record); err != nil {
+		fw.Close()
+		return fmt.Errorf("failed to write parquet: %w", err)
+	}
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("failed to close parquet writer: %w", err)
+	}
+	return nil
+}
+
+// ArrowRenderer renders a result in the Arrow IPC file format.
+type ArrowRenderer struct {
+	rendererBase
+}
+
+func (r *ArrowRenderer) Render(w io.Writer, result *Result) error {
+	record := r.buildRecord(result)
+	defer record.Release()
+
+	fw, err := ipc.NewFileWriter(&positionWriter{w: w}, ipc.WithSchema(record.Schema()))
+	if err != nil {
+		return fmt.Errorf("failed to create arrow writer: %w", err)
+	}
+	if err := fw.Write(record); err != nil {
+		fw.Close()
+		return fmt.Errorf("failed to write arrow: %w", err)
+	}
+	if err := fw.Close(); err != nil {
+		return fmt.Errorf("failed to close arrow writer: %w", err)
+	}
+	return nil
+}
+
+// buildRecord converts a result to an arrow record. A column having a value
+// which does not match its type is stored as string.
+func (r *rendererBase) buildRecord(result *Result) arrow.Record {
+	fields := make([]arrow.Field, len(result.Columns))
+	for i, col := range result.Columns {
+		fields[i] = arrow.Field{Name: col.Name, Type: r.arrowType(result, i), Nullable: true}
+	}
+	schema := arrow.NewSchema(fields, nil)
+
+	b := array.NewRecordBuilder(memory.DefaultAllocator, schema)
+	defer b.Release()
+	for _, row := range result.Rows {
+		for i, v := range row {
+			fb := b.Field(i)
+			if v.IsNull() {
+				fb.AppendNull()
+				continue
+			}
+			switch fb := fb.(type) {
+			case *array.Int64Builder:
+				fb.Append(v.V.(int64))
+			case *array.Float64Builder:
+				fb.Append(v.V.(float64))
+			case *array.BooleanBuilder:
+				fb.Append(v.V.(bool))
+			case *array.TimestampBuilder:
+				fb.Append(arrow.Timestamp(v.V.(time.Time).UnixMicro()))
+			case *array.Date32Builder:
+				fb.Append(arrow.Date32FromTime(v.V.(time.Time)))
+			case *array.StringBuilder:
+				fb.Append(v.String())
+			}
+		}
+	}
+	return b.NewRecord()
+}
+
+func (r *rendererBase) arrowType(result *Result, col int) arrow.DataType {
+	var t arrow.DataType
+	var match func(any) bool
+	switch result.Columns[col].Type {
+	case ColumnTypeInteger:
+		t = arrow.PrimitiveTypes.Int64
+		match = func(v any) bool { _, ok := v.(int64); return ok }
+	case ColumnTypeFloat:
+		t = arrow.PrimitiveTypes.Float64
+		match = func(v any) bool { _, ok := v.(float64); return ok }
+	case ColumnTypeBoolean:
+		t = arrow.FixedWidthTypes.Boolean
+		match = func(v any) bool { _, ok := v.(bool); return ok }
+	case ColumnTypeDatetime:
+		tz := "UTC"
+		if r.Formatter != nil && r.Formatter.Location != nil {
+			tz = arrowTimeZone(r.Formatter.Location)
+		}
+		t = &arrow.TimestampType{Unit: arrow.Microsecond, TimeZone: tz}
+		match = func(v any) bool { _, ok := v.(time.Time); return ok }
+	case ColumnTypeDate:
+		t = arrow.FixedWidthTypes.Date32
+		match = func(v any) bool { _, ok := v.(time.Time); return ok }
+	default:
+		return arrow.BinaryTypes.String
+	}
+	for _, row := range result.Rows {
+		if v := row[col].V; v != nil && !match(v) {
+			return arrow.BinaryTypes.String
+		}
+	}
+	return t
+}
+
+// positionWriter is an io.WriteSeeker which only reports the current
+// position, which is all the arrow file writer needs.
+type positionWriter struct {
+	w   io.Writer
+	pos int64
+}
+
+func (p *positionWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.pos += int64(n)
+	return n, err
+}
+
+func (p *positionWriter) Seek(offset int64, whence int) (int64, error) {
+	if offset != 0 || whence != io.SeekCurrent {
+		return 0, errors.New("seek is not supported")
+	}
+	return p.pos, nil
+}
+
+// arrowTimeZone returns the IANA name of loc for the arrow schema, resolving
+// Local which readers do not accept. It falls back to UTC, which changes
+// only how the timestamps are displayed.
+func arrowTimeZone(loc *time.Location) string {
+	name := loc.String()
+	if loc == time.Local {
+		name = localTimeZoneName()
+	}
+	if name == "" || name == "Local" {
+		return "UTC"
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return "UTC"
+	}
+	return name
+}
+
+func localTimeZoneName() string {
+	if tz, ok := os.LookupEnv("TZ"); ok {
+		return strings.TrimPrefix(tz, ":")
+	}
+	target, err := os.Readlink("/etc/localtime")
+	if err != nil {
+		return ""
+	}
+	if _, name, ok := strings.Cut(target, "zoneinfo/"); ok {
+		return name
+	}
+	return ""
+}
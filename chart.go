@@ -0,0 +1,354 @@
+package redac
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+type chartType int
+
+const (
+	ChartTypeBar chartType = iota
+	ChartTypeLine
+	ChartTypeSparkline
+)
+
+func ParseChartType(s string) (chartType, error) {
+	switch s {
+	case "bar":
+		return ChartTypeBar, nil
+	case "line":
+		return ChartTypeLine, nil
+	case "sparkline":
+		return ChartTypeSparkline, nil
+	}
+	return 0, fmt.Errorf("unknown chart type: %s", s)
+}
+
+const (
+	defaultChartWidth  = 80
+	defaultChartHeight = 15
+)
+
+var (
+	barBlocks       = []rune(" ▏▎▍▌▋▊▉█")
+	sparklineBlocks = []rune("▁▂▃▄▅▆▇█")
+	lineMarkers     = []rune("*+ox#@")
+)
+
+// ChartRenderer draws a chart of the numeric Y columns against the X column.
+// X defaults to the first non-numeric column and Y to the numeric columns.
+type ChartRenderer struct {
+	rendererBase
+	Type   chartType
+	X      string
+	Y      []string
+	Width  int
+	Height int
+}
+
+type chartSeries struct {
+	Name   string
+	Values []float64
+	Valid  []bool
+}
+
+func (s *chartSeries) bounds() (float64, float64, bool) {
+	lo, hi, ok := math.Inf(1), math.Inf(-1), false
+	for i, v := range s.Values {
+		if s.Valid[i] {
+			lo, hi, ok = min(lo, v), max(hi, v), true
+		}
+	}
+	return lo, hi, ok
+}
+
+func (r *ChartRenderer) Render(w io.Writer, result *Result) error {
+	labels, series, err := r.series(result)
+	if err != nil {
+		return err
+	}
+	width := r.Width
+	if width <= 0 {
+		width = defaultChartWidth
+	}
+
+	bw := bufio.NewWriter(w)
+	switch r.Type {
+	case ChartTypeBar:
+		r.renderBar(bw, width, labels, series)
+	case ChartTypeLine:
+		height := r.Height
+		if height <= 0 {
+			height = defaultChartHeight
+		}
+		r.renderLine(bw, width, height, labels, series)
+	case ChartTypeSparkline:
+		r.renderSparkline(bw, width, series)
+	}
+	return bw.Flush()
+}
+
+func (r *ChartRenderer) series(result *Result) ([]string, []*chartSeries, error) {
+	x := -1
+	if r.X != "" {
+		x = slices.IndexFunc(result.Columns, func(c Column) bool { return c.Name == r.X })
+		if x < 0 {
+			return nil, nil, fmt.Errorf("x column not found: %s", r.X)
+		}
+	} else {
+		x = slices.IndexFunc(result.Columns, func(c Column) bool { return !c.IsNumeric() })
+	}
+
+	var ys []int
+	if len(r.Y) > 0 {
+		for _, name := range r.Y {
+			i := slices.IndexFunc(result.Columns, func(c Column) bool { return c.Name == name })
+			if i < 0 {
+				return nil, nil, fmt.Errorf("y column not found: %s", name)
+			}
+			ys = append(ys, i)
+		}
+	} else {
+		for i, c := range result.Columns {
+			if i != x && c.IsNumeric() {
+				ys = append(ys, i)
+			}
+		}
+	}
+	if len(ys) == 0 {
+		return nil, nil, fmt.Errorf("no numeric column to chart")
+	}
+
+	labels := make([]string, len(result.Rows))
+	for i, row := range result.Rows {
+		if x < 0 {
+			labels[i] = strconv.Itoa(i + 1)
+		} else {
+			labels[i] = r.Formatter.Format(result.Columns[x], row[x])
+		}
+	}
+	series := make([]*chartSeries, len(ys))
+	for i, y := range ys {
+		s := &chartSeries{
+			Name:   result.Columns[y].FriendlyName,
+			Values: make([]float64, len(result.Rows)),
+			Valid:  make([]bool, len(result.Rows)),
+		}
+		for j, row := range result.Rows {
+			s.Values[j], s.Valid[j] = chartValue(row[y])
+		}
+		series[i] = s
+	}
+	return labels, series, nil
+}
+
+func chartValue(v Value) (float64, bool) {
+	switch x := v.V.(type) {
+	case int64:
+		return float64(x), true
+	case float64:
+		return x, true
+	case string:
+		f, err := strconv.ParseFloat(x, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// renderBar draws a horizontal bar per row and series, scaled by the largest
+// absolute value. Negative values are drawn with `░`.
+func (r *ChartRenderer) renderBar(w *bufio.Writer, width int, labels []string, series []*chartSeries) {
+	labelWidth, nameWidth, valueWidth := 0, 0, 0
+	scale := 0.0
+	for _, l := range labels {
+		labelWidth = max(labelWidth, runewidth.StringWidth(l))
+	}
+	for _, s := range series {
+		nameWidth = max(nameWidth, runewidth.StringWidth(s.Name))
+		for i, v := range s.Values {
+			if s.Valid[i] {
+				scale = max(scale, math.Abs(v))
+				valueWidth = max(valueWidth, len(formatChartValue(v)))
+			}
+		}
+	}
+	if len(series) == 1 {
+		nameWidth = 0
+	}
+	barWidth := max(width-labelWidth-nameWidth-valueWidth-4, 1)
+
+	for i, label := range labels {
+		for j, s := range series {
+			if j == 0 {
+				w.WriteString(runewidth.FillRight(label, labelWidth))
+			} else {
+				w.WriteString(strings.Repeat(" ", labelWidth))
+			}
+			w.WriteString(" ")
+			if nameWidth > 0 {
+				w.WriteString(runewidth.FillRight(s.Name, nameWidth))
+				w.WriteString(" ")
+			}
+			w.WriteString("│")
+			if s.Valid[i] {
+				w.WriteString(bar(s.Values[i], scale, barWidth))
+				w.WriteString(" ")
+				w.WriteString(formatChartValue(s.Values[i]))
+			}
+			w.WriteString("\n")
+		}
+	}
+}
+
+func bar(v, scale float64, width int) string {
+	if scale == 0 {
+		return ""
+	}
+	n := math.Abs(v) / scale * float64(width)
+	if v < 0 {
+		return strings.Repeat("░", int(math.Round(n)))
+	}
+	eighths := int(math.Round(n * 8))
+	s := strings.Repeat(string(barBlocks[8]), eighths/8)
+	if eighths%8 > 0 {
+		s += string(barBlocks[eighths%8])
+	}
+	return s
+}
+
+// renderLine plots the series on a grid of the height with an axis of the
+// values on the left and the first and last labels below.
+func (r *ChartRenderer) renderLine(w *bufio.Writer, width, height int, labels []string, series []*chartSeries) {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range series {
+		if l, h, ok := s.bounds(); ok {
+			lo, hi = min(lo, l), max(hi, h)
+		}
+	}
+	if math.IsInf(lo, 0) {
+		return
+	}
+	if lo == hi {
+		lo, hi = lo-1, hi+1
+	}
+	axis := []string{formatChartValue(hi), formatChartValue((lo + hi) / 2), formatChartValue(lo)}
+	axisWidth := 0
+	for _, a := range axis {
+		axisWidth = max(axisWidth, len(a))
+	}
+	plotWidth := max(width-axisWidth-2, 2)
+
+	grid := make([][]rune, height)
+	for i := range grid {
+		grid[i] = []rune(strings.Repeat(" ", plotWidth))
+	}
+	rowOf := func(v float64) int {
+		return height - 1 - int(math.Round((v-lo)/(hi-lo)*float64(height-1)))
+	}
+	for k, s := range series {
+		marker := lineMarkers[k%len(lineMarkers)]
+		prevCol, prevRow := -1, 0
+		for i, v := range s.Values {
+			if !s.Valid[i] {
+				prevCol = -1
+				continue
+			}
+			col := 0
+			if len(labels) > 1 {
+				col = int(math.Round(float64(i) * float64(plotWidth-1) / float64(len(labels)-1)))
+			}
+			row := rowOf(v)
+			// interpolate between the points so the line is continuous
+			if prevCol >= 0 {
+				for c := prevCol + 1; c < col; c++ {
+					t := float64(c-prevCol) / float64(col-prevCol)
+					grid[prevRow+int(math.Round(t*float64(row-prevRow)))][c] = marker
+				}
+			}
+			grid[row][col] = marker
+			prevCol, prevRow = col, row
+		}
+	}
+
+	for i, line := range grid {
+		a := ""
+		switch i {
+		case 0:
+			a = axis[0]
+		case height / 2:
+			a = axis[1]
+		case height - 1:
+			a = axis[2]
+		}
+		fmt.Fprintf(w, "%*s ┤%s\n", axisWidth, a, strings.TrimRight(string(line), " "))
+	}
+	fmt.Fprintf(w, "%*s └%s\n", axisWidth, "", strings.Repeat("─", plotWidth))
+	if len(labels) > 0 {
+		first, last := labels[0], labels[len(labels)-1]
+		gap := max(plotWidth-runewidth.StringWidth(first)-runewidth.StringWidth(last), 1)
+		fmt.Fprintf(w, "%*s  %s%s%s\n", axisWidth, "", first, strings.Repeat(" ", gap), last)
+	}
+	if len(series) > 1 {
+		legend := make([]string, len(series))
+		for k, s := range series {
+			legend[k] = string(lineMarkers[k%len(lineMarkers)]) + " " + s.Name
+		}
+		fmt.Fprintf(w, "%*s  %s\n", axisWidth, "", strings.Join(legend, "  "))
+	}
+}
+
+// renderSparkline draws a line per series. Values are averaged into buckets
+// when there are more rows than the width.
+func (r *ChartRenderer) renderSparkline(w *bufio.Writer, width int, series []*chartSeries) {
+	nameWidth := 0
+	for _, s := range series {
+		nameWidth = max(nameWidth, runewidth.StringWidth(s.Name))
+	}
+	for _, s := range series {
+		lo, hi, ok := s.bounds()
+		rangeStr := ""
+		if ok {
+			rangeStr = formatChartValue(lo) + ".." + formatChartValue(hi)
+		}
+		n := min(len(s.Values), max(width-nameWidth-len(rangeStr)-2, 1))
+
+		w.WriteString(runewidth.FillRight(s.Name, nameWidth))
+		w.WriteString(" ")
+		for i := 0; i < n; i++ {
+			sum, count := 0.0, 0
+			for j := i * len(s.Values) / n; j < (i+1)*len(s.Values)/n; j++ {
+				if s.Valid[j] {
+					sum += s.Values[j]
+					count++
+				}
+			}
+			if count == 0 {
+				w.WriteString(" ")
+				continue
+			}
+			level := 0
+			if hi > lo {
+				level = int(math.Round((sum/float64(count) - lo) / (hi - lo) * float64(len(sparklineBlocks)-1)))
+			}
+			w.WriteRune(sparklineBlocks[level])
+		}
+		w.WriteString(" ")
+		w.WriteString(rangeStr)
+		w.WriteString("\n")
+	}
+}
+
+func formatChartValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', 6, 64)
+}
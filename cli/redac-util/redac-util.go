@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/Songmu/prompter"
 	"github.com/go-yushi-nakai/redac"
@@ -18,6 +19,14 @@ func init() {
 	configCmd.AddCommand(listCmd)
 	configCmd.AddCommand(addCmd)
 	configCmd.AddCommand(delCmd)
+
+	addCmd.Flags().String("ca-bundle", "", "CA certificates file to verify the redash server")
+	addCmd.Flags().String("client-cert", "", "client certificate file")
+	addCmd.Flags().String("client-key", "", "client key file")
+	addCmd.Flags().Bool("insecure-skip-verify", false, "skip verification of the server certificate")
+	addCmd.Flags().String("proxy", "", "proxy URL")
+	addCmd.Flags().StringArray("header", nil, "header sent to redash as `Name: value` (repeatable)")
+	addCmd.Flags().String("user-agent", "", "User-Agent sent to redash")
 }
 
 var rootCmd = &cobra.Command{
@@ -62,41 +71,30 @@ var addCmd = &cobra.Command{
 			return
 		}
 
-		name := prompter.Prompt("context name", "")
-		endpoint := prompter.Prompt("redash URL", "")
-		apiKey := prompter.Password("API Key")
-
-		rc, err := redac.NewRedashClient(endpoint, apiKey, logger)
+		cc, err := newConfigContext(cmd)
 		if err != nil {
-			fmt.Printf("failed to create redash client: %s\n", err)
+			fmt.Printf("failed to parse flags: %s\n", err)
 			return
 		}
-		sources, err := rc.GetDataSources(context.Background())
+		cc.Name = prompter.Prompt("context name", "")
+		cc.Endpoint = prompter.Prompt("redash URL", "")
+		cc.APIKey = prompter.Password("API Key")
+
+		rc, err := redac.NewRedashClient(cc.Endpoint, cc.APIKey, logger, cc.ClientOptions()...)
 		if err != nil {
-			fmt.Printf("failed to get data sources: %s\n", err)
+			fmt.Printf("failed to create redash client: %s\n", err)
 			return
 		}
-		fmt.Printf("list of data sources from %s:\n", endpoint)
-		for _, source := range sources {
-			id, ok := source["id"].(float64)
-			if !ok {
-				fmt.Println("failed to parse source ID")
-				return
-			}
-			name, ok := source["name"].(string)
-			if !ok {
-				fmt.Println("failed to parse source name")
-				return
-			}
-			fmt.Printf("  id=%d: %s\n", int(id), name)
+		if err := printDataSources(rc, cc.Endpoint); err != nil {
+			fmt.Printf("failed to get data sources: %s\n", err)
 		}
 		dsIDStr := prompter.Prompt("select source ID", "")
-		dsID, err := strconv.Atoi(dsIDStr)
+		cc.DataSourceID, err = strconv.Atoi(dsIDStr)
 		if err != nil {
 			fmt.Printf("failed to parse source ID: %s\n", err)
 			return
 		}
-		if err := redac.AddConfigContext(name, endpoint, apiKey, dsID); err != nil {
+		if err := redac.AddConfigContextWith(cc); err != nil {
 			fmt.Printf("failed to add context: %s\n", err)
 			return
 		}
@@ -104,6 +102,66 @@ var addCmd = &cobra.Command{
 	},
 }
 
+func newConfigContext(cmd *cobra.Command) (*redac.ConfigContext, error) {
+	cc := &redac.ConfigContext{}
+	var err error
+	if cc.CABundle, err = cmd.Flags().GetString("ca-bundle"); err != nil {
+		return nil, err
+	}
+	if cc.ClientCert, err = cmd.Flags().GetString("client-cert"); err != nil {
+		return nil, err
+	}
+	if cc.ClientKey, err = cmd.Flags().GetString("client-key"); err != nil {
+		return nil, err
+	}
+	if cc.InsecureSkipVerify, err = cmd.Flags().GetBool("insecure-skip-verify"); err != nil {
+		return nil, err
+	}
+	if cc.Proxy, err = cmd.Flags().GetString("proxy"); err != nil {
+		return nil, err
+	}
+	if cc.UserAgent, err = cmd.Flags().GetString("user-agent"); err != nil {
+		return nil, err
+	}
+	headers, err := cmd.Flags().GetStringArray("header")
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range headers {
+		k, v, ok := strings.Cut(h, ":")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid header: %s", h)
+		}
+		if cc.Headers == nil {
+			cc.Headers = map[string]string{}
+		}
+		cc.Headers[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return cc, nil
+}
+
+// printDataSources lists the data sources so that one can be selected. The ID
+// can still be entered by hand when this fails.
+func printDataSources(rc *redac.RedashClient, endpoint string) error {
+	sources, err := rc.GetDataSources(context.Background())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("list of data sources from %s:\n", endpoint)
+	for _, source := range sources {
+		id, ok := source["id"].(float64)
+		if !ok {
+			return fmt.Errorf("failed to parse source ID")
+		}
+		name, ok := source["name"].(string)
+		if !ok {
+			return fmt.Errorf("failed to parse source name")
+		}
+		fmt.Printf("  id=%d: %s\n", int(id), name)
+	}
+	return nil
+}
+
 var delCmd = &cobra.Command{
 	Use: "del",
 	Run: func(cmd *cobra.Command, args []string) {
@@ -1,24 +1,60 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"maps"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Songmu/prompter"
 	"github.com/go-yushi-nakai/redac"
+	"github.com/mattn/go-isatty"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 func init() {
 	rootCmd.SetUsageTemplate(usageForDefault)
 	rootCmd.PersistentFlags().BoolP("version", "v", false, "show version info")
 	rootCmd.PersistentFlags().StringP("eval", "e", "", "evaluate sql")
+	rootCmd.PersistentFlags().Int("query-id", 0, "execute query saved in redash")
+	rootCmd.PersistentFlags().StringArrayP("param", "p", nil, "query parameter as key=value (can be repeated)")
+	rootCmd.PersistentFlags().String("params-file", "", "YAML file of query parameters")
 	rootCmd.PersistentFlags().Bool("no-limit", false, "disalbe auto-limit flag in redash")
+	rootCmd.PersistentFlags().Bool("no-progress", false, "hide job progress on stderr")
 	rootCmd.PersistentFlags().Bool("no-header", false, "hide header line from output")
-	rootCmd.PersistentFlags().StringP("format", "f", "table1", "output format table1/table2/csv/json/yaml (default:table1")
+	rootCmd.PersistentFlags().Bool("no-pager", false, "do not page table output on terminal")
+	rootCmd.PersistentFlags().String("color", "auto", "color table output auto/always/never")
+	rootCmd.PersistentFlags().StringP("format", "f", "table1", "output format table1/table2/vertical/auto/csv/tsv/ltsv/json/json-objects/ndjson/yaml/yaml-objects/markdown/html/xlsx/parquet/arrow/sql/sql-copy/template/chart (default:table1")
+	rootCmd.PersistentFlags().String("null", "", "string to show NULL")
+	rootCmd.PersistentFlags().Int("float-precision", -1, "digits after the decimal point of floats (-1: as many as needed)")
+	rootCmd.PersistentFlags().String("thousands-separator", "", "separator of thousands in numbers")
+	rootCmd.PersistentFlags().String("timezone", "", "time zone to convert datetimes to (e.g. Local, Asia/Tokyo)")
+	rootCmd.PersistentFlags().String("datetime-format", "", "Go layout or rfc3339/datetime/date/unix/unixdate to format datetimes")
+	rootCmd.PersistentFlags().String("date-format", "", "Go layout or rfc3339/datetime/date/unix/unixdate to format dates")
+	rootCmd.PersistentFlags().String("delimiter", ",", "field delimiter of csv output")
+	rootCmd.PersistentFlags().Bool("quote-all", false, "quote all fields of csv output")
+	rootCmd.PersistentFlags().Bool("crlf", false, "use CRLF line endings in csv output")
+	rootCmd.PersistentFlags().String("chart-type", "bar", "chart type bar/line/sparkline of chart output")
+	rootCmd.PersistentFlags().String("chart-x", "", "x column of chart output (default: first non-numeric column)")
+	rootCmd.PersistentFlags().StringSlice("chart-y", nil, "y columns of chart output (default: numeric columns)")
+	rootCmd.PersistentFlags().String("table-name", "result", "table name of sql output")
+	rootCmd.PersistentFlags().Int("batch-size", 100, "rows per INSERT statement of sql output")
+	rootCmd.PersistentFlags().Bool("no-css", false, "do not embed CSS in html output")
+	rootCmd.PersistentFlags().String("template", "", "Go text/template file of template output")
+	rootCmd.PersistentFlags().String("template-string", "", "Go text/template of template output")
+	rootCmd.PersistentFlags().StringP("output", "o", "", "write output to the file instead of stdout (format inferred from extension, gzip for .gz)")
 	rootCmd.PersistentFlags().StringP("timeout", "t", "10s", "timeout")
 	rootCmd.PersistentFlags().StringP("loglevel", "l", "warn", "loglevel(debug/info/warn/error)")
 	rootCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
@@ -32,6 +68,9 @@ const (
 	usageForDefault = `Usage:{{if .Runnable}}
   {{.Use}} [flags...] -e <query_string> <context_name> [args...]
   {{.Use}} [flags...] <query_file> <context_name> [args...]
+  {{.Use}} [flags...] - <context_name> [args...] < <query_file>
+  {{.Use}} [flags...] --query-id <query_id> <context_name> [args...]
+  {{.Use}} shell [flags...] <context_name>
 {{end}}
 Flags:
 {{.LocalFlags.FlagUsages | trimTrailingWhitespaces}}
@@ -57,6 +96,7 @@ Flags:
 var rootCmd = &cobra.Command{
 	Use:   "redac",
 	Short: "tool for redash as command",
+	Args:  cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		if v, _ := cmd.Flags().GetBool("version"); v {
 			fmt.Fprintf(os.Stderr, "%s\n", redac.GetVersion())
@@ -67,21 +107,52 @@ var rootCmd = &cobra.Command{
 			fmt.Fprintln(os.Stderr, err)
 			fmt.Fprintln(os.Stderr, "")
 			cmd.Usage()
-			os.Exit(1)
+			os.Exit(exitCodeUsage)
 		}
-		defer rc.cancel()
 
 		if err, withUsage := rc.Run(cmd, args); err != nil {
 			fmt.Fprintln(os.Stderr, err)
 			if withUsage {
 				fmt.Fprintln(os.Stderr, "")
 				cmd.Usage()
+				os.Exit(exitCodeUsage)
 			}
-			os.Exit(2)
+			os.Exit(exitCode(err))
 		}
 	},
 }
 
+const (
+	exitCodeUsage = iota + 1
+	exitCodeError
+	exitCodeAuth
+	exitCodeNotFound
+	exitCodeRateLimited
+	exitCodeServerError
+	exitCodeJobFailed
+	exitCodeTimeout
+)
+
+func exitCode(err error) int {
+	var apiErr *redac.APIError
+	var jobErr *redac.JobError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.IsAuthError():
+		return exitCodeAuth
+	case errors.As(err, &apiErr) && apiErr.IsNotFound():
+		return exitCodeNotFound
+	case errors.As(err, &apiErr) && apiErr.IsRateLimited():
+		return exitCodeRateLimited
+	case errors.As(err, &apiErr) && apiErr.IsServerError():
+		return exitCodeServerError
+	case errors.As(err, &jobErr):
+		return exitCodeJobFailed
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return exitCodeTimeout
+	}
+	return exitCodeError
+}
+
 func main() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -91,31 +162,28 @@ func main() {
 }
 
 type RedacCommand struct {
-	ctx         context.Context
-	cancel      context.CancelFunc
-	logger      *slog.Logger
-	config      *redac.ConfigFile
-	query       *redac.Query
-	noLimit     bool
-	noHeader    bool
-	renderer    redac.Renderer
-	contextName string
-	queryArgs   []string
+	timeout      time.Duration
+	logger       *slog.Logger
+	config       *redac.ConfigFile
+	query        *redac.Query
+	queryID      int
+	dataSourceID int
+	noLimit      bool
+	noHeader     bool
+	noProgress   bool
+	pager        bool
+	renderer     redac.Renderer
+	formatter    *redac.ValueFormatter
+	binary       bool
+	output       string
+	contextName  string
+	queryArgs    []string
+	namedParams  map[string]string
 }
 
 func NewRedacCommand(cmd *cobra.Command, args []string) (*RedacCommand, error) {
 	c := &RedacCommand{}
 
-	timeoutStr, err := cmd.Flags().GetString("timeout")
-	if err != nil {
-		return nil, fmt.Errorf("failed to get timeout option: %w", err)
-	}
-	timeout, err := time.ParseDuration(timeoutStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse timeout: %w", err)
-	}
-	c.ctx, c.cancel = context.WithTimeout(context.Background(), timeout)
-
 	levelStr, err := cmd.Flags().GetString("loglevel")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get loglevel option: %w", err)
@@ -136,6 +204,12 @@ func NewRedacCommand(cmd *cobra.Command, args []string) (*RedacCommand, error) {
 	}
 	c.noHeader = noHeader
 
+	noProgress, err := cmd.Flags().GetBool("no-progress")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-progress option: %w", err)
+	}
+	c.noProgress = noProgress
+
 	c.logger = logger
 
 	conf, err := redac.LoadConfig()
@@ -158,13 +232,33 @@ func NewRedacCommand(cmd *cobra.Command, args []string) (*RedacCommand, error) {
 
 		usageArgs := c.query.GetParameterStringForUsage()
 		cmd.SetUsageTemplate(usageString(fmt.Sprintf(`-e "%s"`, evalStr), usageArgs))
+	}
 
-		if len(restArgs) == 0 {
-			return nil, fmt.Errorf("no context name specified")
+	if cmd.Flags().Changed("query-id") {
+		if c.query != nil {
+			return nil, fmt.Errorf("--query-id cannot be used with --eval")
+		}
+		queryID, err := cmd.Flags().GetInt("query-id")
+		if err != nil {
+			return nil, fmt.Errorf("failed to get query-id option: %w", err)
+		}
+		c.queryID = queryID
+		cmd.SetUsageTemplate(usageString(fmt.Sprintf("--query-id %d", queryID), "[args...]"))
+	}
+
+	if c.query == nil && c.queryID == 0 && c.isStdinQuery(restArgs) {
+		if len(restArgs) > 0 && restArgs[0] == "-" {
+			restArgs = restArgs[1:]
 		}
+		q, err := redac.LoadQueryFromReader(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get query from stdin: %w", err)
+		}
+		c.query = q
+		cmd.SetUsageTemplate(usageString("-", c.query.GetParameterStringForUsage()))
 	}
 
-	if c.query == nil {
+	if c.query == nil && c.queryID == 0 {
 		if len(restArgs) == 0 {
 			return nil, fmt.Errorf("no query file specified")
 		}
@@ -177,35 +271,314 @@ func NewRedacCommand(cmd *cobra.Command, args []string) (*RedacCommand, error) {
 		c.query = q
 		usageArgs := c.query.GetParameterStringForUsage()
 		cmd.SetUsageTemplate(usageString(filePath, usageArgs))
+	}
 
-		if len(args) == 1 {
-			return nil, fmt.Errorf("no context name specified")
-		}
+	meta := &redac.QueryMetadata{}
+	if c.query != nil {
+		meta = c.query.Metadata
+	}
+	switch {
+	case len(restArgs) > 0 && (meta.Context == "" || c.config.Contexts[restArgs[0]] != nil):
+		c.contextName = restArgs[0]
+		restArgs = restArgs[1:]
+	case meta.Context != "":
+		c.contextName = meta.Context
+	default:
+		return nil, fmt.Errorf("no context name specified")
 	}
-	c.contextName = restArgs[0]
-	restArgs = restArgs[1:]
 	c.queryArgs = restArgs
+	c.dataSourceID = meta.DataSourceID
+
+	timeoutStr, err := cmd.Flags().GetString("timeout")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeout option: %w", err)
+	}
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timeout: %w", err)
+	}
+	if !cmd.Flags().Changed("timeout") && meta.Timeout != 0 {
+		timeout = meta.Timeout
+	}
+	c.timeout = timeout
+
+	if !cmd.Flags().Changed("no-limit") && meta.NoLimit != nil {
+		c.noLimit = *meta.NoLimit
+	}
+
+	c.namedParams = map[string]string{}
+	paramsFile, err := cmd.Flags().GetString("params-file")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get params-file option: %w", err)
+	}
+	if paramsFile != "" {
+		params, err := redac.LoadParamsFile(paramsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load params file: %w", err)
+		}
+		maps.Copy(c.namedParams, params)
+	}
+	paramStrs, err := cmd.Flags().GetStringArray("param")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get param option: %w", err)
+	}
+	params, err := redac.ParseNamedParams(paramStrs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse param option: %w", err)
+	}
+	maps.Copy(c.namedParams, params)
+
+	output, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get output option: %w", err)
+	}
+	c.output = output
 
 	formatStr, err := cmd.Flags().GetString("format")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get format str: %w", err)
 	}
+	if !cmd.Flags().Changed("format") && meta.Format != "" {
+		formatStr = meta.Format
+	}
+	if !cmd.Flags().Changed("format") && c.output != "" {
+		if format, ok := redac.FormatFromPath(c.output); ok {
+			formatStr = format
+		}
+	}
+	if !cmd.Flags().Changed("format") && (cmd.Flags().Changed("template") || cmd.Flags().Changed("template-string")) {
+		formatStr = "template"
+	}
+	width := 0
+	if c.output == "" {
+		width = terminalWidth(os.Stdout)
+	}
+	color, err := useColor(cmd, c.output)
+	if err != nil {
+		return nil, err
+	}
+	c.renderer, c.binary, err = newRenderer(cmd, formatStr, width, color)
+	if err != nil {
+		return nil, err
+	}
+	noPager, err := cmd.Flags().GetBool("no-pager")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get no-pager option: %w", err)
+	}
+	c.pager = !noPager && c.output == "" && isTerminal(os.Stdout) && isPageable(c.renderer)
+	if c.binary && c.output == "" && isTerminal(os.Stdout) {
+		return nil, fmt.Errorf("%s format cannot be written to terminal, use --output", formatStr)
+	}
+
+	formatter, err := newValueFormatter(cmd, c.config.Output)
+	if err != nil {
+		return nil, err
+	}
+	c.formatter = formatter
+
+	return c, nil
+}
+
+// useColor reports whether table output is colored by the --color option.
+func useColor(cmd *cobra.Command, output string) (bool, error) {
+	colorStr, err := cmd.Flags().GetString("color")
+	if err != nil {
+		return false, fmt.Errorf("failed to get color option: %w", err)
+	}
+	switch colorStr {
+	case "auto":
+		return output == "" && isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "", nil
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown color option: %s", colorStr)
+}
+
+// newRenderer returns the renderer of the format and whether its output is
+// binary. width is the terminal width to fit the output in, or zero.
+func newRenderer(cmd *cobra.Command, formatStr string, width int, color bool) (redac.Renderer, bool, error) {
+	var r redac.Renderer
+	binary := false
 	switch formatStr {
 	case "table1":
-		c.renderer = &redac.TableRenderer{TableType: redac.TableType1}
+		r = &redac.TableRenderer{TableType: redac.TableType1, MaxWidth: width, Color: color}
 	case "table2":
-		c.renderer = &redac.TableRenderer{TableType: redac.TableType2}
+		r = &redac.TableRenderer{TableType: redac.TableType2, MaxWidth: width, Color: color}
+	case "vertical":
+		r = &redac.VerticalRenderer{}
+	case "auto":
+		r = &redac.AutoRenderer{Width: width, Color: color}
 	case "csv":
-		c.renderer = &redac.CSVRenderer{}
+		delimiter, err := cmd.Flags().GetString("delimiter")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get delimiter option: %w", err)
+		}
+		if delimiter == `\t` {
+			delimiter = "\t"
+		}
+		if utf8.RuneCountInString(delimiter) != 1 {
+			return nil, false, fmt.Errorf("delimiter must be a character: %q", delimiter)
+		}
+		quoteAll, err := cmd.Flags().GetBool("quote-all")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get quote-all option: %w", err)
+		}
+		crlf, err := cmd.Flags().GetBool("crlf")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get crlf option: %w", err)
+		}
+		d, _ := utf8.DecodeRuneInString(delimiter)
+		r = &redac.CSVRenderer{Delimiter: d, QuoteAll: quoteAll, CRLF: crlf}
+	case "tsv":
+		r = &redac.TSVRenderer{}
+	case "ltsv":
+		r = &redac.LTSVRenderer{}
 	case "json":
-		c.renderer = &redac.JSONRenderer{}
+		r = &redac.JSONRenderer{}
+	case "json-objects":
+		r = &redac.JSONRenderer{Mode: redac.JSONModeObjects}
+	case "ndjson", "jsonl":
+		r = &redac.JSONRenderer{Mode: redac.JSONModeLines}
 	case "yaml":
-		c.renderer = &redac.YAMLRenderer{}
+		r = &redac.YAMLRenderer{}
+	case "yaml-objects":
+		r = &redac.YAMLRenderer{Objects: true}
+	case "markdown", "md":
+		r = &redac.MarkdownRenderer{}
+	case "html":
+		noCSS, err := cmd.Flags().GetBool("no-css")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get no-css option: %w", err)
+		}
+		r = &redac.HTMLRenderer{NoCSS: noCSS}
+	case "xlsx":
+		r = &redac.XLSXRenderer{}
+		binary = true
+	case "parquet":
+		r = &redac.ParquetRenderer{}
+		binary = true
+	case "sql", "sql-copy":
+		tableName, err := cmd.Flags().GetString("table-name")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get table-name option: %w", err)
+		}
+		batchSize, err := cmd.Flags().GetInt("batch-size")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get batch-size option: %w", err)
+		}
+		sr := &redac.SQLRenderer{TableName: tableName, BatchSize: batchSize}
+		if formatStr == "sql-copy" {
+			sr.Mode = redac.SQLModeCopy
+		}
+		r = sr
+	case "arrow", "feather":
+		r = &redac.ArrowRenderer{}
+		binary = true
+	case "chart":
+		chartTypeStr, err := cmd.Flags().GetString("chart-type")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get chart-type option: %w", err)
+		}
+		chartType, err := redac.ParseChartType(chartTypeStr)
+		if err != nil {
+			return nil, false, err
+		}
+		chartX, err := cmd.Flags().GetString("chart-x")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get chart-x option: %w", err)
+		}
+		chartY, err := cmd.Flags().GetStringSlice("chart-y")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get chart-y option: %w", err)
+		}
+		r = &redac.ChartRenderer{Type: chartType, X: chartX, Y: chartY, Width: width}
+	case "template":
+		templateFile, err := cmd.Flags().GetString("template")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get template option: %w", err)
+		}
+		templateStr, err := cmd.Flags().GetString("template-string")
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get template-string option: %w", err)
+		}
+		name := "template-string"
+		switch {
+		case templateFile != "" && templateStr != "":
+			return nil, false, fmt.Errorf("--template cannot be used with --template-string")
+		case templateFile != "":
+			b, err := os.ReadFile(templateFile)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to read template file %s: %w", templateFile, err)
+			}
+			name, templateStr = filepath.Base(templateFile), string(b)
+		case templateStr == "":
+			return nil, false, fmt.Errorf("template format requires --template or --template-string")
+		}
+		tr, err := redac.NewTemplateRenderer(name, templateStr)
+		if err != nil {
+			return nil, false, err
+		}
+		r = tr
 	default:
-		return nil, fmt.Errorf("unknown format: %s", formatStr)
+		return nil, false, fmt.Errorf("unknown format: %s", formatStr)
 	}
+	return r, binary, nil
+}
 
-	return c, nil
+func newValueFormatter(cmd *cobra.Command, oc *redac.OutputConfig) (*redac.ValueFormatter, error) {
+	f, err := oc.ValueFormatter()
+	if err != nil {
+		return nil, fmt.Errorf("invalid output config: %w", err)
+	}
+	flags := cmd.Flags()
+	if flags.Changed("null") {
+		f.Null, _ = flags.GetString("null")
+	}
+	if flags.Changed("float-precision") {
+		f.FloatPrecision, _ = flags.GetInt("float-precision")
+	}
+	if flags.Changed("thousands-separator") {
+		f.ThousandsSeparator, _ = flags.GetString("thousands-separator")
+	}
+	if flags.Changed("timezone") {
+		tz, _ := flags.GetString("timezone")
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone: %w", err)
+		}
+		f.Location = loc
+	}
+	if flags.Changed("datetime-format") {
+		layout, _ := flags.GetString("datetime-format")
+		f.DatetimeLayout = redac.ParseTimeLayout(layout)
+	}
+	if flags.Changed("date-format") {
+		layout, _ := flags.GetString("date-format")
+		f.DateLayout = redac.ParseTimeLayout(layout)
+	}
+	return f, nil
+}
+
+// isStdinQuery reports whether the query is read from stdin, which is when
+// the query file is `-`, or stdin is piped and the first arg is a context
+// name rather than a file.
+func (c *RedacCommand) isStdinQuery(args []string) bool {
+	if len(args) > 0 && args[0] == "-" {
+		return true
+	}
+	if isTerminal(os.Stdin) || len(args) == 0 || c.config.Contexts[args[0]] == nil {
+		return false
+	}
+	if _, err := os.Stat(args[0]); err == nil {
+		return false
+	}
+	fi, err := os.Stdin.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeNamedPipe != 0 || fi.Mode().IsRegular()
 }
 
 func (c *RedacCommand) getConfigContgext(contextName string) (*redac.ConfigContext, error) {
@@ -217,7 +590,7 @@ func (c *RedacCommand) getConfigContgext(contextName string) (*redac.ConfigConte
 }
 
 func (c *RedacCommand) getRedashClient(configCtx *redac.ConfigContext) (*redac.RedashClient, error) {
-	rc, err := redac.NewRedashClient(configCtx.Endpoint, configCtx.APIKey, c.logger)
+	rc, err := redac.NewRedashClient(configCtx.Endpoint, configCtx.APIKey, c.logger, configCtx.ClientOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create redash client: %w", err)
 	}
@@ -235,27 +608,213 @@ func (c *RedacCommand) Run(cmd *cobra.Command, args []string) (error, bool) {
 		return fmt.Errorf("failed to get redash client: %w", err), true
 	}
 
-	params, err := c.query.GetTemplateParams(c.queryArgs)
+	if c.queryID != 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+		saved, err := rc.GetQuery(ctx, c.queryID)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to get saved query: %w", err), false
+		}
+		q, err := redac.NewSavedQuery(saved)
+		if err != nil {
+			return fmt.Errorf("failed to parse saved query: %w", err), false
+		}
+		c.query = q
+		cmd.SetUsageTemplate(usageString(fmt.Sprintf("--query-id %d", c.queryID), c.query.GetParameterStringForUsage()))
+	}
+
+	if isTerminal(os.Stdin) && isTerminal(os.Stdout) {
+		for _, p := range c.query.GetMissingParameters(c.queryArgs, c.namedParams) {
+			v, err := promptParameter(p, stdinPrompt)
+			if err != nil {
+				return fmt.Errorf("failed to read parameter: %w", err), false
+			}
+			c.namedParams[p.Name] = v
+		}
+	}
+
+	params, err := c.query.GetTemplateParams(c.queryArgs, c.namedParams)
 	if err != nil {
 		return fmt.Errorf("failed to get template params: %w", err), true
 	}
 
-	result, err := rc.QueryAndWaitResult(c.ctx, redac.RedashPostQueryResultRequest{
-		ApplyAutoLimit: !c.noLimit,
-		DataSourceID:   configCtx.DataSourceID,
-		Parameters:     params,
-		Query:          c.query.Data,
-	})
+	dataSourceID := configCtx.DataSourceID
+	if c.dataSourceID != 0 {
+		dataSourceID = c.dataSourceID
+	}
+
+	// the timeout starts after the prompts so that typing does not count
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+	// Ctrl-C cancels the job in redash before exiting
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	progress := &progressPrinter{w: os.Stderr}
+	if !c.noProgress && isTerminal(os.Stderr) {
+		rc.OnProgress = progress.Print
+	}
+
+	var result *redac.RedashGetQueryResultResponse
+	if c.queryID != 0 {
+		result, err = rc.QueryIDAndWaitResult(ctx, c.queryID, redac.RedashPostQueryIDResultRequest{
+			ApplyAutoLimit: !c.noLimit,
+			Parameters:     params,
+		})
+	} else {
+		result, err = rc.QueryAndWaitResult(ctx, redac.RedashPostQueryResultRequest{
+			ApplyAutoLimit: !c.noLimit,
+			DataSourceID:   dataSourceID,
+			Parameters:     params,
+			Query:          c.query.Data,
+		})
+	}
+	progress.Clear()
 	if err != nil {
 		return fmt.Errorf("failed to query: %w", err), false
 	}
 
-	tableData := result.GetTable()
-	c.logger.Debug("render", "table", tableData)
+	resultData := result.GetResult()
+	c.logger.Debug("render", "result", resultData)
 	c.renderer.SetShowHeader(!c.noHeader)
-	if err := c.renderer.Render(os.Stdout, tableData); err != nil {
+	c.renderer.SetFormatter(c.formatter)
+	if c.pager {
+		if err := renderWithPager(c.renderer, resultData); err != nil {
+			return err, false
+		}
+		return nil, false
+	}
+	if c.output == "" {
+		if err := c.renderer.Render(os.Stdout, resultData); err != nil {
+			return fmt.Errorf("failed to render: %w", err), false
+		}
+		return nil, false
+	}
+
+	f, err := redac.NewFileOutput(c.output)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err), false
+	}
+	if err := c.renderer.Render(f, resultData); err != nil {
+		f.Abort()
 		return fmt.Errorf("failed to render: %w", err), false
 	}
+	if err := f.Commit(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err), false
+	}
 
 	return nil, false
 }
+
+func isTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+func terminalWidth(f *os.File) int {
+	width, _ := terminalSize(f)
+	return width
+}
+
+func terminalSize(f *os.File) (int, int) {
+	if !isTerminal(f) {
+		return 0, 0
+	}
+	width, height, err := term.GetSize(int(f.Fd()))
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
+func isPageable(r redac.Renderer) bool {
+	switch r.(type) {
+	case *redac.TableRenderer, *redac.VerticalRenderer, *redac.AutoRenderer, *redac.ChartRenderer:
+		return true
+	}
+	return false
+}
+
+// renderWithPager renders the result to stdout through $PAGER when it is
+// longer than the terminal.
+func renderWithPager(r redac.Renderer, result *redac.Result) error {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, result); err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+	_, height := terminalSize(os.Stdout)
+	if bytes.Count(buf.Bytes(), []byte("\n")) < height {
+		_, err := buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-S"}
+	}
+	cmd := exec.Command(pager[0], pager[1:]...)
+	if os.Getenv("LESS") == "" {
+		// let less show colors
+		cmd.Env = append(os.Environ(), "LESS=R")
+	}
+	cmd.Stdin = &buf
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) {
+			_, err := buf.WriteTo(os.Stdout)
+			return err
+		}
+		return fmt.Errorf("failed to run pager: %w", err)
+	}
+	return nil
+}
+
+// promptFunc asks a value with the message and returns def for empty input.
+type promptFunc func(message, def string) (string, error)
+
+func stdinPrompt(message, def string) (string, error) {
+	return prompter.Prompt(message, def), nil
+}
+
+func promptParameter(p *redac.Parameter, prompt promptFunc) (string, error) {
+	for {
+		var v string
+		var err error
+		switch p.Type {
+		case redac.ParameterTypeEnum:
+			for i, o := range p.Options {
+				fmt.Printf("  %d: %s\n", i+1, o)
+			}
+			v, err = prompt(fmt.Sprintf("select %s", p.Name), p.Default)
+			if i, err := strconv.Atoi(v); err == nil && i >= 1 && i <= len(p.Options) {
+				v = p.Options[i-1]
+			}
+		default:
+			v, err = prompt(p.UsageString(), p.Default)
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := p.Parse(v); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		return v, nil
+	}
+}
+
+type progressPrinter struct {
+	w       io.Writer
+	printed bool
+}
+
+func (p *progressPrinter) Print(jp redac.JobProgress) {
+	fmt.Fprintf(p.w, "\r\033[Kjob %s: %s (%.1fs)", jp.JobID, redac.JobStatusString(jp.Status), jp.Elapsed.Seconds())
+	p.printed = true
+}
+
+func (p *progressPrinter) Clear() {
+	if p.printed {
+		fmt.Fprint(p.w, "\r\033[K")
+	}
+}
@@ -0,0 +1,429 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/go-yushi-nakai/redac"
+	"github.com/peterh/liner"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	shellCmd.SetUsageTemplate(usageForShell)
+	shellCmd.SetHelpFunc(func(cmd *cobra.Command, args []string) {
+		cmd.Usage()
+	})
+	rootCmd.AddCommand(shellCmd)
+}
+
+const (
+	usageForShell = `Usage:
+  {{.CommandPath}} [flags...] <context_name>
+
+Queries end with ";". Type \? for meta-commands.
+
+Flags:
+{{.InheritedFlags.FlagUsages | trimTrailingWhitespaces}}
+`
+
+	shellHelp = `\format [name]     show or set the output format
+\timeout [duration] show or set the query timeout
+\limit [on|off]     show or set the auto-limit of redash
+\context [name]     show or switch the context
+\x [on|off]         toggle the expanded (vertical) display
+\q                  quit
+`
+)
+
+var shellCmd = &cobra.Command{
+	Use:   "shell",
+	Short: "interactive shell for a context",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		sh, err := NewShell(cmd, args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			fmt.Fprintln(os.Stderr, "")
+			cmd.Usage()
+			os.Exit(exitCodeUsage)
+		}
+		if err := sh.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(exitCode(err))
+		}
+	},
+}
+
+type Shell struct {
+	cmd         *cobra.Command
+	logger      *slog.Logger
+	config      *redac.ConfigFile
+	contextName string
+	configCtx   *redac.ConfigContext
+	client      *redac.RedashClient
+	formatter   *redac.ValueFormatter
+	format      string
+	timeout     time.Duration
+	noLimit     bool
+	noHeader    bool
+	noProgress  bool
+	noPager     bool
+	expanded    bool
+	line        *liner.State
+	history     []string
+}
+
+func NewShell(cmd *cobra.Command, contextName string) (*Shell, error) {
+	s := &Shell{cmd: cmd}
+
+	levelStr, err := cmd.Flags().GetString("loglevel")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loglevel option: %w", err)
+	}
+	s.logger, err = redac.NewLogger(levelStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create logger: %w", err)
+	}
+	s.config, err = redac.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	format, err := cmd.Flags().GetString("format")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get format str: %w", err)
+	}
+	if err := s.setFormat(format); err != nil {
+		return nil, err
+	}
+	timeoutStr, err := cmd.Flags().GetString("timeout")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeout option: %w", err)
+	}
+	s.timeout, err = time.ParseDuration(timeoutStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timeout: %w", err)
+	}
+	if s.noLimit, err = cmd.Flags().GetBool("no-limit"); err != nil {
+		return nil, fmt.Errorf("failed to get no-limit option: %w", err)
+	}
+	if s.noHeader, err = cmd.Flags().GetBool("no-header"); err != nil {
+		return nil, fmt.Errorf("failed to get no-header option: %w", err)
+	}
+	if s.noProgress, err = cmd.Flags().GetBool("no-progress"); err != nil {
+		return nil, fmt.Errorf("failed to get no-progress option: %w", err)
+	}
+	if s.noPager, err = cmd.Flags().GetBool("no-pager"); err != nil {
+		return nil, fmt.Errorf("failed to get no-pager option: %w", err)
+	}
+	s.formatter, err = newValueFormatter(cmd, s.config.Output)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.setContext(contextName); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Shell) setContext(name string) error {
+	configCtx := s.config.Contexts[name]
+	if configCtx == nil {
+		return fmt.Errorf("context not found: %s", name)
+	}
+	client, err := redac.NewRedashClient(configCtx.Endpoint, configCtx.APIKey, s.logger, configCtx.ClientOptions()...)
+	if err != nil {
+		return fmt.Errorf("failed to create redash client: %w", err)
+	}
+	s.contextName, s.configCtx, s.client = name, configCtx, client
+	return nil
+}
+
+func (s *Shell) setFormat(format string) error {
+	_, binary, err := newRenderer(s.cmd, format, 0, false)
+	if err != nil {
+		return err
+	}
+	if binary {
+		return fmt.Errorf("%s format cannot be written to terminal", format)
+	}
+	s.format = format
+	return nil
+}
+
+func (s *Shell) Run() error {
+	s.line = liner.NewLiner()
+	defer s.line.Close()
+	s.line.SetCtrlCAborts(true)
+	s.line.SetMultiLineMode(true)
+
+	historyPath, err := redac.HistoryFile()
+	if err != nil {
+		s.logger.Warn("history is disabled", "error", err)
+	} else {
+		s.loadHistory(historyPath)
+		defer s.saveHistory(historyPath)
+	}
+
+	var buf strings.Builder
+	for {
+		prompt := s.contextName + "=> "
+		if buf.Len() > 0 {
+			prompt = s.contextName + "-> "
+		}
+		input, err := s.line.Prompt(prompt)
+		if errors.Is(err, liner.ErrPromptAborted) {
+			buf.Reset()
+			continue
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read line: %w", err)
+		}
+
+		trimmed := strings.TrimSpace(input)
+		if buf.Len() == 0 {
+			if trimmed == "" {
+				continue
+			}
+			if strings.HasPrefix(trimmed, `\`) {
+				s.appendHistory(trimmed)
+				quit, err := s.metaCommand(trimmed)
+				if err != nil {
+					fmt.Fprintln(os.Stderr, err)
+				}
+				if quit {
+					return nil
+				}
+				continue
+			}
+		}
+		buf.WriteString(input)
+		buf.WriteString("\n")
+		if !strings.HasSuffix(trimmed, ";") {
+			continue
+		}
+
+		query := strings.TrimSpace(buf.String())
+		buf.Reset()
+		s.appendHistory(query)
+		if err := s.execute(strings.TrimSuffix(query, ";")); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+const maxHistory = 1000
+
+// the history file has an entry per line, so newlines in queries are escaped
+var (
+	historyEscaper   = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	historyUnescaper = strings.NewReplacer(`\\`, `\`, `\n`, "\n")
+)
+
+func (s *Shell) appendHistory(entry string) {
+	s.line.AppendHistory(entry)
+	s.history = append(s.history, entry)
+	if len(s.history) > maxHistory {
+		s.history = s.history[len(s.history)-maxHistory:]
+	}
+}
+
+func (s *Shell) loadHistory(path string) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		if line != "" {
+			s.appendHistory(historyUnescaper.Replace(line))
+		}
+	}
+}
+
+func (s *Shell) saveHistory(path string) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		s.logger.Warn("failed to save history", "error", err)
+		return
+	}
+	defer f.Close()
+	// queries may contain secrets, as psql keeps its history private
+	if err := f.Chmod(0600); err != nil {
+		s.logger.Warn("failed to set permission on history", "error", err)
+	}
+	w := bufio.NewWriter(f)
+	for _, entry := range s.history {
+		w.WriteString(historyEscaper.Replace(entry))
+		w.WriteString("\n")
+	}
+	if err := w.Flush(); err != nil {
+		s.logger.Warn("failed to save history", "error", err)
+	}
+}
+
+func (s *Shell) metaCommand(input string) (bool, error) {
+	fields := strings.Fields(input)
+	name, args := fields[0], fields[1:]
+	arg := ""
+	if len(args) > 0 {
+		arg = args[0]
+	}
+
+	switch name {
+	case `\q`, `\quit`:
+		return true, nil
+	case `\?`, `\h`, `\help`:
+		fmt.Print(shellHelp)
+	case `\format`, `\f`:
+		if arg != "" {
+			if err := s.setFormat(arg); err != nil {
+				return false, err
+			}
+		}
+		fmt.Printf("Output format is %s.\n", s.format)
+	case `\timeout`:
+		if arg != "" {
+			timeout, err := time.ParseDuration(arg)
+			if err != nil {
+				return false, fmt.Errorf("failed to parse timeout: %w", err)
+			}
+			s.timeout = timeout
+		}
+		fmt.Printf("Timeout is %s.\n", s.timeout)
+	case `\limit`:
+		switch arg {
+		case "":
+		case "on":
+			s.noLimit = false
+		case "off":
+			s.noLimit = true
+		default:
+			return false, fmt.Errorf(`\limit expects on or off: %s`, arg)
+		}
+		fmt.Printf("Auto-limit is %s.\n", onOff(!s.noLimit))
+	case `\context`, `\c`:
+		if arg != "" {
+			if err := s.setContext(arg); err != nil {
+				return false, err
+			}
+		}
+		fmt.Printf("Context is %s.\n", s.contextName)
+	case `\x`:
+		switch arg {
+		case "":
+			s.expanded = !s.expanded
+		case "on":
+			s.expanded = true
+		case "off":
+			s.expanded = false
+		default:
+			return false, fmt.Errorf(`\x expects on or off: %s`, arg)
+		}
+		fmt.Printf("Expanded display is %s.\n", onOff(s.expanded))
+	default:
+		return false, fmt.Errorf(`invalid command %s, try \? for help`, name)
+	}
+	return false, nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+func (s *Shell) prompt(message, def string) (string, error) {
+	if def != "" {
+		message += " [" + def + "]"
+	}
+	v, err := s.line.Prompt(message + ": ")
+	if err != nil {
+		return "", err
+	}
+	if v == "" {
+		return def, nil
+	}
+	return v, nil
+}
+
+func (s *Shell) execute(sql string) error {
+	q, err := redac.NewQuery(sql)
+	if err != nil {
+		return fmt.Errorf("failed to parse query: %w", err)
+	}
+	named := map[string]string{}
+	for _, p := range q.GetMissingParameters(nil, named) {
+		v, err := promptParameter(p, s.prompt)
+		if err != nil {
+			return fmt.Errorf("failed to read parameter: %w", err)
+		}
+		named[p.Name] = v
+	}
+	params, err := q.GetTemplateParams(nil, named)
+	if err != nil {
+		return fmt.Errorf("failed to get template params: %w", err)
+	}
+
+	dataSourceID := s.configCtx.DataSourceID
+	if q.Metadata.DataSourceID != 0 {
+		dataSourceID = q.Metadata.DataSourceID
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+	// Ctrl-C cancels the running query instead of the shell
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	progress := &progressPrinter{w: os.Stderr}
+	s.client.OnProgress = nil
+	if !s.noProgress && isTerminal(os.Stderr) {
+		s.client.OnProgress = progress.Print
+	}
+	result, err := s.client.QueryAndWaitResult(ctx, redac.RedashPostQueryResultRequest{
+		ApplyAutoLimit: !s.noLimit,
+		DataSourceID:   dataSourceID,
+		Parameters:     params,
+		Query:          q.Data,
+	})
+	progress.Clear()
+	if err != nil {
+		return fmt.Errorf("failed to query: %w", err)
+	}
+
+	format := s.format
+	if s.expanded {
+		format = "vertical"
+	}
+	color, err := useColor(s.cmd, "")
+	if err != nil {
+		return err
+	}
+	r, _, err := newRenderer(s.cmd, format, terminalWidth(os.Stdout), color)
+	if err != nil {
+		return err
+	}
+	r.SetShowHeader(!s.noHeader)
+	r.SetFormatter(s.formatter)
+	if !s.noPager && isTerminal(os.Stdout) && isPageable(r) {
+		return renderWithPager(r, result.GetResult())
+	}
+	if err := r.Render(os.Stdout, result.GetResult()); err != nil {
+		return fmt.Errorf("failed to render: %w", err)
+	}
+	return nil
+}
@@ -4,38 +4,109 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/adrg/xdg"
 )
 
 type ConfigFile struct {
 	Contexts map[string]*ConfigContext `json:"contexts"`
+	Output   *OutputConfig             `json:"output,omitempty"`
+}
+
+// OutputConfig is the default formatting of values in the output.
+type OutputConfig struct {
+	Null               string `json:"null,omitempty"`
+	FloatPrecision     *int   `json:"floatPrecision,omitempty"`
+	ThousandsSeparator string `json:"thousandsSeparator,omitempty"`
+	Timezone           string `json:"timezone,omitempty"`
+	DatetimeFormat     string `json:"datetimeFormat,omitempty"`
+	DateFormat         string `json:"dateFormat,omitempty"`
+}
+
+func (c *OutputConfig) ValueFormatter() (*ValueFormatter, error) {
+	f := NewValueFormatter()
+	if c == nil {
+		return f, nil
+	}
+	f.Null = c.Null
+	if c.FloatPrecision != nil {
+		f.FloatPrecision = *c.FloatPrecision
+	}
+	f.ThousandsSeparator = c.ThousandsSeparator
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load timezone: %w", err)
+		}
+		f.Location = loc
+	}
+	f.DatetimeLayout = ParseTimeLayout(c.DatetimeFormat)
+	f.DateLayout = ParseTimeLayout(c.DateFormat)
+	return f, nil
 }
 
 type ConfigContext struct {
-	Name         string `json:"name"`
-	Endpoint     string `json:"endpoint"`
-	APIKey       string `json:"apiKey"`
-	DataSourceID int    `json:"dataSourceID"`
+	Name               string            `json:"name"`
+	Endpoint           string            `json:"endpoint"`
+	APIKey             string            `json:"apiKey"`
+	DataSourceID       int               `json:"dataSourceID"`
+	CABundle           string            `json:"caBundle,omitempty"`
+	ClientCert         string            `json:"clientCert,omitempty"`
+	ClientKey          string            `json:"clientKey,omitempty"`
+	InsecureSkipVerify bool              `json:"insecureSkipVerify,omitempty"`
+	Proxy              string            `json:"proxy,omitempty"`
+	Headers            map[string]string `json:"headers,omitempty"`
+	UserAgent          string            `json:"userAgent,omitempty"`
 }
 
-var configFile = "redac/config.json"
+func (c *ConfigContext) ClientOptions() []ClientOption {
+	var opts []ClientOption
+	if c.CABundle != "" {
+		opts = append(opts, WithCABundle(c.CABundle))
+	}
+	if c.ClientCert != "" || c.ClientKey != "" {
+		opts = append(opts, WithClientCertificate(c.ClientCert, c.ClientKey))
+	}
+	if c.InsecureSkipVerify {
+		opts = append(opts, WithInsecureSkipVerify(true))
+	}
+	if c.Proxy != "" {
+		opts = append(opts, WithProxy(c.Proxy))
+	}
+	for k, v := range c.Headers {
+		opts = append(opts, WithHeader(k, v))
+	}
+	if c.UserAgent != "" {
+		opts = append(opts, WithUserAgent(c.UserAgent))
+	}
+	return opts
+}
+
+var (
+	configFile  = "redac/config.json"
+	historyFile = "redac/history"
+)
 
 func AddConfigContext(name, endpoint, apiKey string, dsID int) error {
-	cf, err := LoadConfig()
-	if err != nil {
-		return fmt.Errorf("failed to load config: %w", err)
-	}
-	cc := ConfigContext{
+	return AddConfigContextWith(&ConfigContext{
 		Name:         name,
 		Endpoint:     endpoint,
 		APIKey:       apiKey,
 		DataSourceID: dsID,
+	})
+}
+
+// AddConfigContextWith adds the context including its connection settings.
+func AddConfigContextWith(cc *ConfigContext) error {
+	cf, err := LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
 	}
 	if _, ok := cf.Contexts[cc.Name]; ok {
 		return fmt.Errorf("context %s already exists", cc.Name)
 	}
-	cf.Contexts[cc.Name] = &cc
+	cf.Contexts[cc.Name] = cc
 
 	if err := SaveConfig(cf); err != nil {
 		return fmt.Errorf("failed to save config: %w", err)
@@ -82,6 +153,15 @@ func LoadConfig() (*ConfigFile, error) {
 	return &cf, nil
 }
 
+// HistoryFile returns the path of the history file of the shell.
+func HistoryFile() (string, error) {
+	path, err := xdg.StateFile(historyFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to get history path: %w", err)
+	}
+	return path, nil
+}
+
 func SaveConfig(c *ConfigFile) error {
 	configFilePath, err := xdg.ConfigFile(configFile)
 	if err != nil {
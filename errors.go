@@ -0,0 +1,85 @@
+package redac
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// APIError is returned when redash responds with a status other than 200.
+type APIError struct {
+	Method     string
+	Endpoint   string
+	StatusCode int
+	Body       string
+	RetryAfter time.Duration
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	return &APIError{
+		Method:     resp.Request.Method,
+		Endpoint:   resp.Request.URL.String(),
+		StatusCode: resp.StatusCode,
+		Body:       string(body),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s failed, status=%d, body=%s", e.Method, e.Endpoint, e.StatusCode, e.Body)
+}
+
+func (e *APIError) IsAuthError() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+}
+
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound
+}
+
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests
+}
+
+func (e *APIError) IsServerError() bool {
+	return e.StatusCode >= 500
+}
+
+// IsTemporary reports whether the request may succeed when it is retried.
+func (e *APIError) IsTemporary() bool {
+	switch e.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// JobError is returned when a query job fails or is cancelled in redash.
+type JobError struct {
+	JobID   string
+	Status  int
+	Message string
+}
+
+func (e *JobError) Error() string {
+	if e.Status == RedashJobStatusCancelled {
+		return fmt.Sprintf("job %s is cancelled in redash", e.JobID)
+	}
+	return fmt.Sprintf("job %s is failed: %s", e.JobID, e.Message)
+}
+
+func parseRetryAfter(s string) time.Duration {
+	if s == "" {
+		return 0
+	}
+	if sec, err := strconv.Atoi(s); err == nil && sec > 0 {
+		return time.Duration(sec) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
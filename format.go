@@ -0,0 +1,154 @@
+package redac
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ValueFormatter formats values of a result according to the column types.
+// The zero value formats values as they are returned from redash.
+type ValueFormatter struct {
+	Null string
+	// FloatPrecision is the number of digits after the decimal point, -1 for as many as needed.
+	FloatPrecision     int
+	ThousandsSeparator string
+	// Location converts datetime values to the time zone.
+	Location       *time.Location
+	DatetimeLayout string
+	DateLayout     string
+}
+
+// LayoutUnix is the layout to format datetimes and dates as seconds since the
+// Unix epoch.
+const LayoutUnix = "unix"
+
+func NewValueFormatter() *ValueFormatter {
+	return &ValueFormatter{FloatPrecision: -1}
+}
+
+func (f *ValueFormatter) Format(col Column, v Value) string {
+	if f == nil {
+		return v.String()
+	}
+	switch x := v.V.(type) {
+	case nil:
+		return f.Null
+	case int64:
+		return f.groupThousands(strconv.FormatInt(x, 10))
+	case float64:
+		return f.groupThousands(strconv.FormatFloat(x, 'f', f.FloatPrecision, 64))
+	case time.Time:
+		if s, ok := f.formatTime(col, x); ok {
+			return s
+		}
+	}
+	return v.String()
+}
+
+// Native returns the value for typed outputs such as json and yaml. Numbers
+// and booleans keep their types and NULL is nil.
+func (f *ValueFormatter) Native(col Column, v Value) any {
+	if f == nil || v.IsNull() {
+		return v.Raw
+	}
+	switch x := v.V.(type) {
+	case float64:
+		if f.FloatPrecision >= 0 && col.Type == ColumnTypeFloat {
+			p := math.Pow10(f.FloatPrecision)
+			return json.Number(strconv.FormatFloat(math.Round(x*p)/p, 'f', -1, 64))
+		}
+	case time.Time:
+		if s, ok := f.formatTime(col, x); ok {
+			if f.layout(col) == LayoutUnix {
+				return json.Number(s)
+			}
+			return s
+		}
+	}
+	return v.Raw
+}
+
+func (f *ValueFormatter) layout(col Column) string {
+	if col.Type == ColumnTypeDate {
+		return f.DateLayout
+	}
+	return f.DatetimeLayout
+}
+
+func (f *ValueFormatter) formatTime(col Column, t time.Time) (string, bool) {
+	layout := f.layout(col)
+	if col.Type == ColumnTypeDate {
+		if layout == "" {
+			return "", false
+		}
+	} else {
+		if f.Location == nil && layout == "" {
+			return "", false
+		}
+		if f.Location != nil {
+			t = t.In(f.Location)
+		}
+		if layout == "" {
+			layout = time.RFC3339
+		}
+	}
+	if layout == LayoutUnix {
+		return strconv.FormatInt(t.Unix(), 10), true
+	}
+	return t.Format(layout), true
+}
+
+func (f *ValueFormatter) groupThousands(s string) string {
+	if f.ThousandsSeparator == "" {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(s, ".")
+	if len(intPart) <= 3 {
+		return sign + s
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	head := len(intPart) % 3
+	if head > 0 {
+		b.WriteString(intPart[:head])
+	}
+	for i := head; i < len(intPart); i += 3 {
+		if i > 0 {
+			b.WriteString(f.ThousandsSeparator)
+		}
+		b.WriteString(intPart[i : i+3])
+	}
+	if hasFrac {
+		b.WriteString(".")
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// ParseTimeLayout converts names of well-known layouts to Go layouts. Other
+// strings are returned as they are.
+func ParseTimeLayout(s string) string {
+	switch strings.ToLower(s) {
+	case "rfc3339":
+		return time.RFC3339
+	case "rfc3339nano":
+		return time.RFC3339Nano
+	case "datetime":
+		return time.DateTime
+	case "date":
+		return time.DateOnly
+	case "unix":
+		return LayoutUnix
+	case "unixdate":
+		return time.UnixDate
+	}
+	return s
+}
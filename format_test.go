@@ -0,0 +1,31 @@
+package redac
+
+import "testing"
+
+func TestGroupThousands(t *testing.T) {
+	tests := []struct {
+		name string
+		sep  string
+		in   string
+		want string
+	}{
+		{name: "no separator", sep: "", in: "1234567", want: "1234567"},
+		{name: "short", sep: ",", in: "123", want: "123"},
+		{name: "four digits", sep: ",", in: "1234", want: "1,234"},
+		{name: "multiple of three", sep: ",", in: "123456789", want: "123,456,789"},
+		{name: "negative", sep: ",", in: "-1234567", want: "-1,234,567"},
+		{name: "negative short", sep: ",", in: "-123", want: "-123"},
+		{name: "fraction", sep: ",", in: "1234.5678", want: "1,234.5678"},
+		{name: "negative fraction", sep: ",", in: "-1234567.25", want: "-1,234,567.25"},
+		{name: "short fraction", sep: ",", in: "-0.5", want: "-0.5"},
+		{name: "other separator", sep: "_", in: "1000000", want: "1_000_000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &ValueFormatter{ThousandsSeparator: tt.sep}
+			if got := f.groupThousands(tt.in); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
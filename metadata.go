@@ -0,0 +1,185 @@
+package redac
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	metadataCommentPrefix = "-- redac:"
+	yamlHeaderDelimiter   = "---"
+)
+
+var metadataKVRegexp = regexp.MustCompile(`([^\s=]+)=("(?:[^"\\]|\\.)*"|\S*)`)
+
+// QueryMetadata is the front-matter of a query, given as `-- redac: key=value ...`
+// comment lines or a YAML header surrounded by `---` lines.
+type QueryMetadata struct {
+	Context      string
+	DataSourceID int
+	Format       string
+	Timeout      time.Duration
+	NoLimit      *bool
+	Parameters   map[string]*Parameter
+}
+
+// parseFrontMatter parses the front-matter at the head of s and returns the
+// metadata and the query without the YAML header.
+func parseFrontMatter(s string) (*QueryMetadata, string, error) {
+	m := &QueryMetadata{Parameters: map[string]*Parameter{}}
+
+	lines := strings.SplitAfter(s, "\n")
+	start := 0
+	for start < len(lines) && strings.TrimSpace(lines[start]) == "" {
+		start++
+	}
+	if start < len(lines) && strings.TrimSpace(lines[start]) == yamlHeaderDelimiter {
+		end := start + 1
+		for end < len(lines) && strings.TrimSpace(lines[end]) != yamlHeaderDelimiter {
+			end++
+		}
+		// `---` is also a SQL comment, so the lines are a header only when
+		// they make a YAML mapping
+		header := strings.Join(lines[start+1:min(end, len(lines))], "")
+		if end < len(lines) && isYAMLMapping(header) {
+			if err := m.loadYAML(header); err != nil {
+				return nil, "", fmt.Errorf("failed to parse yaml header: %w", err)
+			}
+			// blank out the header so that line numbers in the query stay the same
+			for i := start; i <= end; i++ {
+				lines[i] = "\n"
+			}
+			s = strings.Join(lines, "")
+		}
+	}
+
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "--") {
+			break
+		}
+		kvs, ok := strings.CutPrefix(line, metadataCommentPrefix)
+		if !ok {
+			continue
+		}
+		for _, kv := range metadataKVRegexp.FindAllStringSubmatch(kvs, -1) {
+			v := kv[2]
+			if strings.HasPrefix(v, `"`) {
+				uv, err := strconv.Unquote(v)
+				if err != nil {
+					return nil, "", fmt.Errorf("syntax error at line %d: invalid value %s", i+1, v)
+				}
+				v = uv
+			}
+			if err := m.set(kv[1], v); err != nil {
+				return nil, "", fmt.Errorf("syntax error at line %d: %w", i+1, err)
+			}
+		}
+	}
+	return m, s, nil
+}
+
+func isYAMLMapping(s string) bool {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		return false
+	}
+	return len(doc.Content) == 1 && doc.Content[0].Kind == yaml.MappingNode
+}
+
+func (m *QueryMetadata) loadYAML(s string) error {
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal([]byte(s), &nodes); err != nil {
+		return err
+	}
+	for k, node := range nodes {
+		if k != "parameters" {
+			v, err := paramStringFromNode(&node)
+			if err != nil {
+				return fmt.Errorf("invalid value of %s: %w", k, err)
+			}
+			if err := m.set(k, v); err != nil {
+				return err
+			}
+			continue
+		}
+
+		var params map[string]map[string]yaml.Node
+		if err := node.Decode(&params); err != nil {
+			return fmt.Errorf("invalid parameters: %w", err)
+		}
+		for name, attrs := range params {
+			for attr, n := range attrs {
+				v, err := paramStringFromNode(&n)
+				if err != nil {
+					return fmt.Errorf("invalid value of parameter %s.%s: %w", name, attr, err)
+				}
+				if err := m.set(fmt.Sprintf("param.%s.%s", name, attr), v); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (m *QueryMetadata) set(key, value string) error {
+	switch key {
+	case "context":
+		m.Context = value
+	case "data-source-id", "data-source":
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", key, value)
+		}
+		m.DataSourceID = id
+	case "format":
+		m.Format = value
+	case "timeout":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+		m.Timeout = d
+	case "no-limit":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %s", key, value)
+		}
+		m.NoLimit = &b
+	default:
+		rest, ok := strings.CutPrefix(key, "param.")
+		if !ok {
+			return fmt.Errorf("unknown metadata key: %s", key)
+		}
+		name, attr, ok := strings.Cut(rest, ".")
+		if !ok {
+			return fmt.Errorf("invalid metadata key: %s, expected param.<name>.<type|options|default>", key)
+		}
+		p, ok := m.Parameters[name]
+		if !ok {
+			p = &Parameter{Name: name}
+			m.Parameters[name] = p
+		}
+		switch attr {
+		case "type":
+			t, err := ParseParameterType(value)
+			if err != nil {
+				return fmt.Errorf("parameter %s: %w", name, err)
+			}
+			p.Type = t
+		case "options":
+			p.Options = splitList(value)
+		case "default":
+			p.Default, p.HasDefault = value, true
+		default:
+			return fmt.Errorf("unknown parameter attribute: %s", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package redac
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var extensionFormats = map[string]string{
+	".txt":      "table1",
+	".csv":      "csv",
+	".tsv":      "tsv",
+	".ltsv":     "ltsv",
+	".json":     "json",
+	".ndjson":   "ndjson",
+	".jsonl":    "ndjson",
+	".yaml":     "yaml",
+	".yml":      "yaml",
+	".md":       "markdown",
+	".markdown": "markdown",
+	".html":     "html",
+	".htm":      "html",
+	".xlsx":     "xlsx",
+	".parquet":  "parquet",
+	".arrow":    "arrow",
+	".feather":  "arrow",
+	".sql":      "sql",
+}
+
+// FormatFromPath returns the output format for the extension of path, which
+// may be followed by `.gz`.
+func FormatFromPath(path string) (string, bool) {
+	path = strings.TrimSuffix(strings.ToLower(path), ".gz")
+	format, ok := extensionFormats[filepath.Ext(path)]
+	return format, ok
+}
+
+// FileOutput writes to a temporary file which replaces the file at Path on
+// Commit, so that the file is never left half written. Paths ending with
+// `.gz` are gzip compressed.
+type FileOutput struct {
+	Path string
+	tmp  *os.File
+	gz   *gzip.Writer
+	w    io.Writer
+}
+
+func NewFileOutput(path string) (*FileOutput, error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	o := &FileOutput{Path: path, tmp: tmp, w: tmp}
+	if strings.HasSuffix(strings.ToLower(path), ".gz") {
+		o.gz = gzip.NewWriter(tmp)
+		o.w = o.gz
+	}
+	return o, nil
+}
+
+func (o *FileOutput) Write(b []byte) (int, error) {
+	return o.w.Write(b)
+}
+
+func (o *FileOutput) Commit() error {
+	if o.gz != nil {
+		if err := o.gz.Close(); err != nil {
+			o.Abort()
+			return fmt.Errorf("failed to compress output: %w", err)
+		}
+	}
+	if err := o.tmp.Sync(); err != nil {
+		o.Abort()
+		return fmt.Errorf("failed to sync output: %w", err)
+	}
+	if err := o.tmp.Close(); err != nil {
+		os.Remove(o.tmp.Name())
+		return fmt.Errorf("failed to close output: %w", err)
+	}
+	if err := os.Chmod(o.tmp.Name(), o.mode()); err != nil {
+		os.Remove(o.tmp.Name())
+		return fmt.Errorf("failed to set permission on output: %w", err)
+	}
+	if err := os.Rename(o.tmp.Name(), o.Path); err != nil {
+		os.Remove(o.tmp.Name())
+		return fmt.Errorf("failed to rename output: %w", err)
+	}
+	return nil
+}
+
+// mode returns the permission of the file being replaced, or the default
+// permission under the umask for a new file.
+func (o *FileOutput) mode() os.FileMode {
+	if fi, err := os.Stat(o.Path); err == nil {
+		return fi.Mode().Perm()
+	}
+	return 0666 &^ umask()
+}
+
+// Abort removes the temporary file and leaves the file at Path as it was.
+func (o *FileOutput) Abort() {
+	o.tmp.Close()
+	os.Remove(o.tmp.Name())
+}
@@ -0,0 +1,14 @@
+//go:build unix
+
+package redac
+
+import (
+	"os"
+	"syscall"
+)
+
+func umask() os.FileMode {
+	mask := syscall.Umask(0)
+	syscall.Umask(mask)
+	return os.FileMode(mask)
+}
@@ -0,0 +1,308 @@
+package redac
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var ErrInvalidParameterValue = errors.New("invalid parameter value")
+
+type ParameterType string
+
+const (
+	ParameterTypeText          ParameterType = "text"
+	ParameterTypeNumber        ParameterType = "number"
+	ParameterTypeDate          ParameterType = "date"
+	ParameterTypeDatetime      ParameterType = "datetime"
+	ParameterTypeDateRange     ParameterType = "date-range"
+	ParameterTypeDatetimeRange ParameterType = "datetime-range"
+	ParameterTypeEnum          ParameterType = "enum"
+	ParameterTypeMultiSelect   ParameterType = "multi-select"
+)
+
+const rangeSeparator = ".."
+
+var (
+	dateLayouts     = []string{"2006-01-02"}
+	datetimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", time.RFC3339}
+
+	paramDeclRegexp = regexp.MustCompile(`^\s*--\s*@param\s+(\S+)\s+(\S+)(?:\s+(\S.*?))?\s*$`)
+)
+
+type Parameter struct {
+	Name       string
+	Type       ParameterType
+	Options    []string
+	Default    string
+	HasDefault bool
+}
+
+func ParseParameterType(s string) (ParameterType, error) {
+	switch t := ParameterType(s); t {
+	case ParameterTypeText, ParameterTypeNumber, ParameterTypeDate, ParameterTypeDatetime,
+		ParameterTypeDateRange, ParameterTypeDatetimeRange, ParameterTypeEnum, ParameterTypeMultiSelect:
+		return t, nil
+	}
+	return "", fmt.Errorf("unknown parameter type: %s", s)
+}
+
+func newParameterFromRedash(rp RedashQueryParameter) *Parameter {
+	p := &Parameter{Name: rp.Name, Type: ParameterTypeText}
+	switch rp.Type {
+	case "number":
+		p.Type = ParameterTypeNumber
+	case "date":
+		p.Type = ParameterTypeDate
+	case "datetime-local", "datetime-with-seconds":
+		p.Type = ParameterTypeDatetime
+	case "date-range":
+		p.Type = ParameterTypeDateRange
+	case "datetime-range", "datetime-range-with-seconds":
+		p.Type = ParameterTypeDatetimeRange
+	case "enum":
+		p.Type = ParameterTypeEnum
+		if rp.MultiValuesOptions != nil {
+			p.Type = ParameterTypeMultiSelect
+		}
+		for _, o := range strings.Split(rp.EnumOptions, "\n") {
+			if o = strings.TrimSpace(o); o != "" {
+				p.Options = append(p.Options, o)
+			}
+		}
+	}
+	return p
+}
+
+// parseParameterDeclaration parses a `-- @param <name> <type> [options]` line.
+func parseParameterDeclaration(line string) (*Parameter, bool, error) {
+	m := paramDeclRegexp.FindStringSubmatch(line)
+	if m == nil {
+		return nil, false, nil
+	}
+	t, err := ParseParameterType(m[2])
+	if err != nil {
+		return nil, true, fmt.Errorf("parameter %s: %w", m[1], err)
+	}
+	p := &Parameter{Name: m[1], Type: t}
+	if m[3] != "" {
+		p.Options = splitList(m[3])
+	}
+	return p, true, nil
+}
+
+// merge overrides the declaration of p by the fields set in o.
+func (p *Parameter) merge(o *Parameter) {
+	if o.Type != "" {
+		p.Type = o.Type
+	}
+	if len(o.Options) > 0 {
+		p.Options = o.Options
+	}
+	if o.HasDefault {
+		p.Default, p.HasDefault = o.Default, true
+	}
+}
+
+func (p *Parameter) validate() error {
+	if (p.Type == ParameterTypeEnum || p.Type == ParameterTypeMultiSelect) && len(p.Options) == 0 {
+		return fmt.Errorf("parameter %s: %s requires options", p.Name, p.Type)
+	}
+	if p.HasDefault {
+		if _, err := p.Parse(p.Default); err != nil {
+			return fmt.Errorf("invalid default: %w", err)
+		}
+	}
+	return nil
+}
+
+func (p *Parameter) Parse(s string) (any, error) {
+	switch p.Type {
+	case ParameterTypeNumber:
+		s = strings.TrimSpace(s)
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, p.invalid(s, "not a number")
+		}
+		return json.Number(s), nil
+	case ParameterTypeDate:
+		if _, err := parseTime(s, dateLayouts); err != nil {
+			return nil, p.invalid(s, "expected YYYY-MM-DD")
+		}
+		return s, nil
+	case ParameterTypeDatetime:
+		if _, err := parseTime(s, datetimeLayouts); err != nil {
+			return nil, p.invalid(s, "expected YYYY-MM-DD hh:mm[:ss]")
+		}
+		return s, nil
+	case ParameterTypeDateRange, ParameterTypeDatetimeRange:
+		layouts, hint := dateLayouts, "expected YYYY-MM-DD..YYYY-MM-DD"
+		if p.Type == ParameterTypeDatetimeRange {
+			layouts, hint = datetimeLayouts, "expected YYYY-MM-DD hh:mm..YYYY-MM-DD hh:mm"
+		}
+		start, end, ok := strings.Cut(s, rangeSeparator)
+		if !ok {
+			return nil, p.invalid(s, hint)
+		}
+		start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+		startTime, err := parseTime(start, layouts)
+		if err != nil {
+			return nil, p.invalid(s, hint)
+		}
+		endTime, err := parseTime(end, layouts)
+		if err != nil {
+			return nil, p.invalid(s, hint)
+		}
+		if startTime.After(endTime) {
+			return nil, p.invalid(s, "start is after end")
+		}
+		return map[string]any{"start": start, "end": end}, nil
+	case ParameterTypeEnum:
+		if len(p.Options) > 0 && !slices.Contains(p.Options, s) {
+			return nil, p.invalid(s, fmt.Sprintf("must be one of %s", strings.Join(p.Options, "/")))
+		}
+		return s, nil
+	case ParameterTypeMultiSelect:
+		values := splitList(s)
+		for _, v := range values {
+			if len(p.Options) > 0 && !slices.Contains(p.Options, v) {
+				return nil, p.invalid(v, fmt.Sprintf("must be any of %s", strings.Join(p.Options, "/")))
+			}
+		}
+		return values, nil
+	}
+	return s, nil
+}
+
+func (p *Parameter) UsageString() string {
+	switch p.Type {
+	case "", ParameterTypeText:
+		return p.Name
+	case ParameterTypeEnum:
+		return fmt.Sprintf("%s:%s", p.Name, strings.Join(p.Options, "|"))
+	case ParameterTypeMultiSelect:
+		return fmt.Sprintf("%s:%s,...", p.Name, strings.Join(p.Options, "|"))
+	}
+	return fmt.Sprintf("%s:%s", p.Name, p.Type)
+}
+
+func (p *Parameter) invalid(v, reason string) error {
+	return fmt.Errorf("%w for %s (%s): %q, %s", ErrInvalidParameterValue, p.Name, p.Type, v, reason)
+}
+
+// ParseNamedParams parses `key=value` strings.
+func ParseNamedParams(kvs []string) (map[string]string, error) {
+	params := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", kv)
+		}
+		params[strings.TrimSpace(k)] = v
+	}
+	return params, nil
+}
+
+// LoadParamsFile loads named parameters from a YAML file. Lists are joined
+// with `,` and `{start, end}` mappings are converted to `start..end`.
+func LoadParamsFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
+	}
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(b, &nodes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal params file %s: %w", path, err)
+	}
+
+	params := make(map[string]string, len(nodes))
+	for k, node := range nodes {
+		v, err := paramStringFromNode(&node)
+		if err != nil {
+			return nil, fmt.Errorf("invalid parameter %s in %s: %w", k, path, err)
+		}
+		params[k] = v
+	}
+	return params, nil
+}
+
+// redashParameterValueString converts a parameter value of a saved query to
+// the string form accepted by Parameter.Parse.
+func redashParameterValueString(v any) (string, bool) {
+	switch v := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return v, true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case []any:
+		values := make([]string, len(v))
+		for i, e := range v {
+			values[i] = fmt.Sprint(e)
+		}
+		return strings.Join(values, ","), true
+	case map[string]any:
+		start, ok1 := v["start"].(string)
+		end, ok2 := v["end"].(string)
+		if !ok1 || !ok2 {
+			return "", false
+		}
+		return start + rangeSeparator + end, true
+	}
+	return fmt.Sprint(v), true
+}
+
+func paramStringFromNode(node *yaml.Node) (string, error) {
+	switch node.Kind {
+	case yaml.ScalarNode:
+		return node.Value, nil
+	case yaml.SequenceNode:
+		values := make([]string, len(node.Content))
+		for i, n := range node.Content {
+			if n.Kind != yaml.ScalarNode {
+				return "", fmt.Errorf("nested value is not supported")
+			}
+			values[i] = n.Value
+		}
+		return strings.Join(values, ","), nil
+	case yaml.MappingNode:
+		var r struct {
+			Start string `yaml:"start"`
+			End   string `yaml:"end"`
+		}
+		if err := node.Decode(&r); err != nil || r.Start == "" || r.End == "" {
+			return "", fmt.Errorf("mapping value must have start and end")
+		}
+		return r.Start + rangeSeparator + r.End, nil
+	}
+	return "", fmt.Errorf("unsupported value")
+}
+
+func parseTime(s string, layouts []string) (time.Time, error) {
+	var err error
+	for _, layout := range layouts {
+		var t time.Time
+		if t, err = time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
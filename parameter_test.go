@@ -0,0 +1,77 @@
+package redac
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParameterParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		param   Parameter
+		input   string
+		want    any
+		invalid bool
+	}{
+		{name: "text", param: Parameter{Type: ParameterTypeText}, input: " a b ", want: " a b "},
+		{name: "number int", param: Parameter{Type: ParameterTypeNumber}, input: " 42 ", want: json.Number("42")},
+		{name: "number float", param: Parameter{Type: ParameterTypeNumber}, input: "-1.5e3", want: json.Number("-1.5e3")},
+		{name: "number invalid", param: Parameter{Type: ParameterTypeNumber}, input: "1a", invalid: true},
+		{name: "date", param: Parameter{Type: ParameterTypeDate}, input: "2024-02-29", want: "2024-02-29"},
+		{name: "date invalid", param: Parameter{Type: ParameterTypeDate}, input: "2023-02-29", invalid: true},
+		{name: "datetime", param: Parameter{Type: ParameterTypeDatetime}, input: "2024-01-02 03:04", want: "2024-01-02 03:04"},
+		{name: "datetime seconds", param: Parameter{Type: ParameterTypeDatetime}, input: "2024-01-02T03:04:05", want: "2024-01-02T03:04:05"},
+		{name: "datetime invalid", param: Parameter{Type: ParameterTypeDatetime}, input: "2024-01-02", invalid: true},
+		{
+			name:  "date range",
+			param: Parameter{Type: ParameterTypeDateRange},
+			input: "2024-01-01 .. 2024-01-31",
+			want:  map[string]any{"start": "2024-01-01", "end": "2024-01-31"},
+		},
+		{name: "date range reversed", param: Parameter{Type: ParameterTypeDateRange}, input: "2024-02-01..2024-01-01", invalid: true},
+		{name: "date range no separator", param: Parameter{Type: ParameterTypeDateRange}, input: "2024-01-01", invalid: true},
+		{
+			name:  "datetime range",
+			param: Parameter{Type: ParameterTypeDatetimeRange},
+			input: "2024-01-01 00:00..2024-01-01 12:00",
+			want:  map[string]any{"start": "2024-01-01 00:00", "end": "2024-01-01 12:00"},
+		},
+		{
+			name:  "datetime range mixed layouts",
+			param: Parameter{Type: ParameterTypeDatetimeRange},
+			input: "2024-01-01T10:00..2024-01-01 11:00",
+			want:  map[string]any{"start": "2024-01-01T10:00", "end": "2024-01-01 11:00"},
+		},
+		{name: "datetime range reversed", param: Parameter{Type: ParameterTypeDatetimeRange}, input: "2024-01-01 11:00..2024-01-01T10:00", invalid: true},
+		{name: "datetime range invalid", param: Parameter{Type: ParameterTypeDatetimeRange}, input: "2024-01-01..2024-01-02", invalid: true},
+		{name: "enum", param: Parameter{Type: ParameterTypeEnum, Options: []string{"a", "b"}}, input: "b", want: "b"},
+		{name: "enum invalid", param: Parameter{Type: ParameterTypeEnum, Options: []string{"a", "b"}}, input: "c", invalid: true},
+		{
+			name:  "multi-select",
+			param: Parameter{Type: ParameterTypeMultiSelect, Options: []string{"a", "b", "c"}},
+			input: "a, c",
+			want:  []string{"a", "c"},
+		},
+		{name: "multi-select invalid", param: Parameter{Type: ParameterTypeMultiSelect, Options: []string{"a"}}, input: "a,d", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.param.Name = "p"
+			got, err := tt.param.Parse(tt.input)
+			if tt.invalid {
+				if !errors.Is(err, ErrInvalidParameterValue) {
+					t.Errorf("got %v, want ErrInvalidParameterValue", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
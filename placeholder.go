@@ -0,0 +1,199 @@
+package redac
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrQueryNestedParam  = errors.New("nested `{{`")
+	ErrQueryInvalidParam = errors.New("invalid parameter")
+
+	placeholderKeyRegexp = regexp.MustCompile(`^[\p{L}\p{N}_-]+(\.[\p{L}\p{N}_-]+)*$`)
+)
+
+type QuerySyntaxError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, column %d: %s", e.Line, e.Column, e.Err)
+}
+
+func (e *QuerySyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// placeholder is a `{{ key | default: value }}` in a query. Start and End are
+// the byte offsets of `{{` and the end of `}}`.
+type placeholder struct {
+	Key        string
+	Default    string
+	HasDefault bool
+	Start      int
+	End        int
+}
+
+// span is a byte range of a query.
+type span struct {
+	Start int
+	End   int
+}
+
+// scanPlaceholders finds the placeholders and the comments in s. Comments are
+// skipped, and braces in string literals and quoted identifiers are only taken
+// as a placeholder when they make a valid one.
+func scanPlaceholders(s string) ([]placeholder, []span, error) {
+	var phs []placeholder
+	var comments []span
+	for i := 0; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], "--"):
+			end := strings.IndexByte(s[i:], '\n')
+			if end == -1 {
+				return phs, append(comments, span{i, len(s)}), nil
+			}
+			comments = append(comments, span{i, i + end})
+			i += end + 1
+		case strings.HasPrefix(s[i:], "/*"):
+			end := strings.Index(s[i+2:], "*/")
+			if end == -1 {
+				return phs, append(comments, span{i, len(s)}), nil
+			}
+			comments = append(comments, span{i, i + end + 4})
+			i += end + 4
+		case s[i] == '\'' || s[i] == '"' || s[i] == '`':
+			quote := s[i]
+			i++
+			for i < len(s) {
+				if strings.HasPrefix(s[i:], "{{") {
+					if ph, err := parsePlaceholderAt(s, i); err == nil && !strings.Contains(s[i:ph.End], "\n") {
+						phs = append(phs, ph)
+						i = ph.End
+						continue
+					}
+				}
+				if s[i] == quote {
+					if i+1 < len(s) && s[i+1] == quote {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+		case strings.HasPrefix(s[i:], "{{"):
+			ph, err := parsePlaceholderAt(s, i)
+			if err != nil {
+				return nil, nil, err
+			}
+			phs = append(phs, ph)
+			i = ph.End
+		case strings.HasPrefix(s[i:], "}}"):
+			return nil, nil, newQuerySyntaxError(s, i, ErrQueryOpenParamNotFound)
+		default:
+			i++
+		}
+	}
+	return phs, comments, nil
+}
+
+// parsePlaceholderAt parses the placeholder starting at s[start:], which has `{{`.
+func parsePlaceholderAt(s string, start int) (placeholder, error) {
+	ph := placeholder{Start: start}
+	rest := s[start+2:]
+	end := strings.Index(rest, "}}")
+	if end == -1 {
+		return ph, newQuerySyntaxError(s, start, ErrorQueryCloseParamNotFound)
+	}
+	if nested := strings.Index(rest[:end], "{{"); nested != -1 {
+		return ph, newQuerySyntaxError(s, start+2+nested, ErrQueryNestedParam)
+	}
+	expr := rest[:end]
+	ph.End = start + 2 + end + 2
+
+	filters := splitFilters(expr)
+	ph.Key = strings.TrimSpace(filters[0])
+	if !placeholderKeyRegexp.MatchString(ph.Key) {
+		return ph, newQuerySyntaxError(s, start, fmt.Errorf("%w name %q in {{%s}}", ErrQueryInvalidParam, ph.Key, expr))
+	}
+	for _, f := range filters[1:] {
+		name, arg, _ := strings.Cut(f, ":")
+		if strings.TrimSpace(name) != "default" {
+			return ph, newQuerySyntaxError(s, start, fmt.Errorf("%w filter %q in {{%s}}", ErrQueryInvalidParam, strings.TrimSpace(name), expr))
+		}
+		ph.Default, ph.HasDefault = unquote(strings.TrimSpace(arg)), true
+	}
+	return ph, nil
+}
+
+// splitFilters splits a placeholder expression by `|` outside quotes.
+func splitFilters(expr string) []string {
+	var filters []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == '|':
+			filters = append(filters, expr[start:i])
+			start = i + 1
+		}
+	}
+	return append(filters, expr[start:])
+}
+
+// splitBraces puts a space between `{{` and `}}` so that mustache does not
+// take them as a tag.
+func splitBraces(s string) string {
+	for strings.Contains(s, "{{") || strings.Contains(s, "}}") {
+		s = strings.ReplaceAll(s, "{{", "{ {")
+		s = strings.ReplaceAll(s, "}}", "} }")
+	}
+	return s
+}
+
+// rewrite replaces a span of a query with Text.
+type rewrite struct {
+	span
+	Text string
+}
+
+func applyRewrites(s string, rewrites []rewrite) string {
+	slices.SortFunc(rewrites, func(a, b rewrite) int { return a.Start - b.Start })
+	var b strings.Builder
+	pos := 0
+	for _, r := range rewrites {
+		b.WriteString(s[pos:r.Start])
+		b.WriteString(r.Text)
+		pos = r.End
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+func newQuerySyntaxError(s string, offset int, err error) *QuerySyntaxError {
+	before := s[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := utf8.RuneCountInString(before[strings.LastIndexByte(before, '\n')+1:]) + 1
+	return &QuerySyntaxError{Line: line, Column: col, Err: err}
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
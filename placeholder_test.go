@@ -0,0 +1,222 @@
+package redac
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestScanPlaceholders(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []placeholder
+	}{
+		{
+			name:  "no placeholder",
+			query: "select 1",
+		},
+		{
+			name:  "spaces",
+			query: "select {{a}}, {{  b  }}",
+			want: []placeholder{
+				{Key: "a", Start: 7, End: 12},
+				{Key: "b", Start: 14, End: 23},
+			},
+		},
+		{
+			name:  "range keys",
+			query: "where d between '{{ d.start }}' and '{{ d.end }}'",
+			want: []placeholder{
+				{Key: "d.start", Start: 17, End: 30},
+				{Key: "d.end", Start: 37, End: 48},
+			},
+		},
+		{
+			name:  "default filter",
+			query: "limit {{ n | default: 10 }}",
+			want: []placeholder{
+				{Key: "n", Default: "10", HasDefault: true, Start: 6, End: 27},
+			},
+		},
+		{
+			name:  "quoted default with pipe",
+			query: "{{ x | default: 'a|b' }}",
+			want: []placeholder{
+				{Key: "x", Default: "a|b", HasDefault: true, Start: 0, End: 24},
+			},
+		},
+		{
+			name:  "line comment",
+			query: "select 1 -- {{ a }}\n, {{ b }}",
+			want: []placeholder{
+				{Key: "b", Start: 22, End: 29},
+			},
+		},
+		{
+			name:  "block comment",
+			query: "select /* {{ a\n}} */ {{ b }}",
+			want: []placeholder{
+				{Key: "b", Start: 21, End: 28},
+			},
+		},
+		{
+			name:  "braces in string literal",
+			query: "select '}}{{', '{{ a }}'",
+			want: []placeholder{
+				{Key: "a", Start: 16, End: 23},
+			},
+		},
+		{
+			name:  "escaped quote in string literal",
+			query: "select 'it''s }}', {{ a }}",
+			want: []placeholder{
+				{Key: "a", Start: 19, End: 26},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := scanPlaceholders(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanPlaceholdersError(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		err    error
+		line   int
+		column int
+	}{
+		{
+			name:   "unclosed",
+			query:  "select\n  {{ a",
+			err:    ErrorQueryCloseParamNotFound,
+			line:   2,
+			column: 3,
+		},
+		{
+			name:   "stray close",
+			query:  "select a }}",
+			err:    ErrQueryOpenParamNotFound,
+			line:   1,
+			column: 10,
+		},
+		{
+			name:   "nested",
+			query:  "select {{ a {{ b }} }}",
+			err:    ErrQueryNestedParam,
+			line:   1,
+			column: 13,
+		},
+		{
+			name:   "invalid name",
+			query:  "select\n\n{{ a b }}",
+			err:    ErrQueryInvalidParam,
+			line:   3,
+			column: 1,
+		},
+		{
+			name:   "unknown filter",
+			query:  "select {{ a | upper }}",
+			err:    ErrQueryInvalidParam,
+			line:   1,
+			column: 8,
+		},
+		{
+			name:   "column counts runes",
+			query:  "select 'あ', }}",
+			err:    ErrQueryOpenParamNotFound,
+			line:   1,
+			column: 13,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := scanPlaceholders(tt.query)
+			var se *QuerySyntaxError
+			if !errors.As(err, &se) {
+				t.Fatalf("got %v, want QuerySyntaxError", err)
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("got %v, want %v", err, tt.err)
+			}
+			if se.Line != tt.line || se.Column != tt.column {
+				t.Errorf("got line %d column %d, want line %d column %d", se.Line, se.Column, tt.line, tt.column)
+			}
+		})
+	}
+}
+
+func TestNewQueryParameters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []*Parameter
+		data  string
+	}{
+		{
+			name:  "dedup",
+			query: "select {{ a }}, {{ b }}, {{a}}",
+			want: []*Parameter{
+				{Name: "a", Type: ParameterTypeText},
+				{Name: "b", Type: ParameterTypeText},
+			},
+			data: "select {{ a }}, {{ b }}, {{a}}",
+		},
+		{
+			name:  "range",
+			query: "where d >= '{{ d.start }}' and d < '{{ d.end }}'",
+			want: []*Parameter{
+				{Name: "d", Type: ParameterTypeDateRange},
+			},
+			data: "where d >= '{{ d.start }}' and d < '{{ d.end }}'",
+		},
+		{
+			name:  "default filter is removed",
+			query: "limit {{ n | default: 10 }} offset {{ n }}",
+			want: []*Parameter{
+				{Name: "n", Type: ParameterTypeText, Default: "10", HasDefault: true},
+			},
+			data: "limit {{ n }} offset {{ n }}",
+		},
+		{
+			name:  "braces in comments are split",
+			query: "select {{ a }} -- {{ b }}\n/* {{{ c }}} */",
+			want: []*Parameter{
+				{Name: "a", Type: ParameterTypeText},
+			},
+			data: "select {{ a }} -- { { b } }\n/* { { { c } } } */",
+		},
+		{
+			name:  "declared type",
+			query: "-- @param n number\nlimit {{ n }}",
+			want: []*Parameter{
+				{Name: "n", Type: ParameterTypeNumber},
+			},
+			data: "-- @param n number\nlimit {{ n }}",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := NewQuery(tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(q.Parameters, tt.want) {
+				t.Errorf("got %+v, want %+v", q.Parameters, tt.want)
+			}
+			if q.Data != tt.data {
+				t.Errorf("got data %q, want %q", q.Data, tt.data)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package redac
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// PollPolicy is the exponential backoff of polling a job.
+type PollPolicy struct {
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	Multiplier      float64
+}
+
+var DefaultPollPolicy = PollPolicy{
+	InitialInterval: 200 * time.Millisecond,
+	MaxInterval:     5 * time.Second,
+	Multiplier:      1.5,
+}
+
+func (p PollPolicy) next(interval time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		interval = time.Duration(float64(interval) * p.Multiplier)
+	}
+	if p.MaxInterval > 0 && interval > p.MaxInterval {
+		interval = p.MaxInterval
+	}
+	return interval
+}
+
+type JobProgress struct {
+	JobID   string
+	Status  int
+	Elapsed time.Duration
+}
+
+func JobStatusString(status int) string {
+	switch status {
+	case RedashJobStatusPending:
+		return "pending"
+	case RedashJobStatusStarted:
+		return "started"
+	case RedashJobStatusSuccess:
+		return "success"
+	case RedashJobStatusFailure:
+		return "failure"
+	case RedashJobStatusCancelled:
+		return "cancelled"
+	}
+	return fmt.Sprintf("unknown(%d)", status)
+}
+
+func (rc *RedashClient) waitJobResult(ctx context.Context, jobID string) (*RedashGetQueryResultResponse, error) {
+	start := time.Now()
+	interval := rc.PollPolicy.InitialInterval
+	if interval <= 0 {
+		interval = DefaultPollPolicy.InitialInterval
+	}
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			rc.cleanupJob(jobID)
+			return nil, fmt.Errorf("job %s is cancelled: %w", jobID, ctx.Err())
+		case <-timer.C:
+		}
+
+		job, err := rc.GetJob(ctx, jobID)
+		if err != nil {
+			if ctx.Err() != nil {
+				rc.cleanupJob(jobID)
+				return nil, fmt.Errorf("job %s is cancelled: %w", jobID, ctx.Err())
+			}
+			return nil, err
+		}
+		rc.Logger.Debug("job status", "job_id", jobID, "status", JobStatusString(job.Job.Status))
+		if rc.OnProgress != nil {
+			rc.OnProgress(JobProgress{JobID: jobID, Status: job.Job.Status, Elapsed: time.Since(start)})
+		}
+
+		switch job.Job.Status {
+		case RedashJobStatusPending, RedashJobStatusStarted:
+		case RedashJobStatusSuccess:
+			return rc.GetQueryResult(ctx, job.Job.QueryResultID)
+		case RedashJobStatusFailure, RedashJobStatusCancelled:
+			return nil, &JobError{JobID: jobID, Status: job.Job.Status, Message: job.Job.Error}
+		default:
+			return nil, fmt.Errorf("job %s has unknown status %d", jobID, job.Job.Status)
+		}
+
+		interval = rc.PollPolicy.next(interval)
+		timer.Reset(interval)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
@@ -15,39 +16,190 @@ var (
 
 type Query struct {
 	Data       string
-	Parameters []string
+	Parameters []*Parameter
+	Metadata   *QueryMetadata
 }
 
 func NewQuery(s string) (*Query, error) {
+	meta, s, err := parseFrontMatter(s)
+	if err != nil {
+		return nil, err
+	}
 	q := &Query{}
 	q.Data = s
-	pos := 0
-	for {
-		key, idx, err := findParameter(s)
-		if err == ErrQueryOpenParamNotFound {
-			return q, nil
+	q.Metadata = meta
+
+	decls := map[string]*Parameter{}
+	for i, line := range strings.Split(s, "\n") {
+		p, ok, err := parseParameterDeclaration(line)
+		if err != nil {
+			return nil, fmt.Errorf("syntax error at line %d: %w", i+1, err)
+		}
+		if ok {
+			decls[p.Name] = p
+		}
+	}
+	for name, mp := range meta.Parameters {
+		p, ok := decls[name]
+		if !ok {
+			p = &Parameter{Name: name}
+			decls[name] = p
+		}
+		p.merge(mp)
+	}
+
+	phs, comments, err := scanPlaceholders(s)
+	if err != nil {
+		return nil, err
+	}
+	var rewrites []rewrite
+	for _, ph := range phs {
+		q.addParameter(ph.Key, decls)
+		if !ph.HasDefault {
+			continue
+		}
+		if p := q.GetParameter(parameterName(ph.Key)); !p.HasDefault {
+			p.Default, p.HasDefault = ph.Default, true
 		}
-		if err == ErrorQueryCloseParamNotFound {
-			return nil, fmt.Errorf("syntax error at idx %d: %w", pos, err)
+		// redash does not know filters, so the placeholder is rewritten without them
+		rewrites = append(rewrites, rewrite{span{ph.Start, ph.End}, surroundString(ph.Key, "{{ ", " }}")})
+	}
+	for _, c := range comments {
+		// redash renders comments as mustache too, so braces in them are split
+		if text := splitBraces(s[c.Start:c.End]); text != s[c.Start:c.End] {
+			rewrites = append(rewrites, rewrite{c, text})
 		}
-		q.Parameters = append(q.Parameters, key)
-		pos += idx
-		s = s[idx:]
 	}
+	q.Data = applyRewrites(s, rewrites)
+
+	for name, p := range decls {
+		if q.GetParameter(name) == nil {
+			return nil, fmt.Errorf("parameter %s is declared but not used", name)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("invalid parameter declaration: %w", err)
+		}
+	}
+	return q, nil
+}
+
+// NewSavedQuery builds a Query from a query saved in redash. When the saved
+// query has a parameter schema, it is used as is and the text is not parsed,
+// since redash accepts mustache syntax which NewQuery rejects.
+func NewSavedQuery(saved *RedashGetQueryResponse) (*Query, error) {
+	if len(saved.Options.Parameters) == 0 {
+		return NewQuery(saved.Query)
+	}
+	q := &Query{
+		Data:       saved.Query,
+		Parameters: make([]*Parameter, len(saved.Options.Parameters)),
+		Metadata:   &QueryMetadata{},
+	}
+	for i, rp := range saved.Options.Parameters {
+		p := newParameterFromRedash(rp)
+		if def, ok := redashParameterValueString(rp.Value); ok {
+			if _, err := p.Parse(def); err == nil {
+				p.Default, p.HasDefault = def, true
+			}
+		}
+		q.Parameters[i] = p
+	}
+	return q, nil
 }
 
-func (q *Query) GetTemplateParams(args []string) (map[string]any, error) {
-	if len(args) != len(q.Parameters) {
-		return nil, fmt.Errorf("argument error, expected %+v, got %+v", q.Parameters, args)
+func (q *Query) addParameter(key string, decls map[string]*Parameter) {
+	name := parameterName(key)
+	isRange := name != key
+	if q.GetParameter(name) != nil {
+		return
+	}
+
+	p, ok := decls[name]
+	if !ok {
+		p = &Parameter{Name: name}
+	}
+	if p.Type == "" {
+		p.Type = ParameterTypeText
+		if isRange {
+			p.Type = ParameterTypeDateRange
+		}
+	}
+	q.Parameters = append(q.Parameters, p)
+}
+
+func (q *Query) GetParameter(name string) *Parameter {
+	for _, p := range q.Parameters {
+		if p.Name == name {
+			return p
+		}
+	}
+	return nil
+}
+
+// GetTemplateParams builds redash parameters from named values and positional
+// args. Positional args are assigned in order to the parameters not given by
+// name, and the rest of the parameters take their default values.
+func (q *Query) GetTemplateParams(args []string, named map[string]string) (map[string]any, error) {
+	values, missing, err := q.assignParameterValues(args, named)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		names := make([]string, len(missing))
+		for i, p := range missing {
+			names[i] = p.Name
+		}
+		return nil, fmt.Errorf("argument error, missing parameters %s, expected %s", strings.Join(names, ", "), q.GetParameterStringForUsage())
 	}
 
 	params := map[string]any{}
-	for i, key := range q.Parameters {
-		params[key] = args[i]
+	for _, p := range q.Parameters {
+		v, err := p.Parse(values[p.Name])
+		if err != nil {
+			return nil, err
+		}
+		params[p.Name] = v
 	}
 	return params, nil
 }
 
+// GetMissingParameters returns the parameters which are given neither by
+// args nor by name and have no default value.
+func (q *Query) GetMissingParameters(args []string, named map[string]string) []*Parameter {
+	_, missing, _ := q.assignParameterValues(args, named)
+	return missing
+}
+
+func (q *Query) assignParameterValues(args []string, named map[string]string) (map[string]string, []*Parameter, error) {
+	for name := range named {
+		if q.GetParameter(name) == nil {
+			return nil, nil, fmt.Errorf("argument error, unknown parameter %s, expected %s", name, q.GetParameterStringForUsage())
+		}
+	}
+
+	values := map[string]string{}
+	var missing []*Parameter
+	for _, p := range q.Parameters {
+		s, ok := named[p.Name]
+		if !ok {
+			switch {
+			case len(args) > 0:
+				s, args = args[0], args[1:]
+			case p.HasDefault:
+				s = p.Default
+			default:
+				missing = append(missing, p)
+				continue
+			}
+		}
+		values[p.Name] = s
+	}
+	if len(args) > 0 {
+		return nil, nil, fmt.Errorf("argument error, too many arguments %+v, expected %s", args, q.GetParameterStringForUsage())
+	}
+	return values, missing, nil
+}
+
 func (q *Query) GetParameterStringForUsage() string {
 	if len(q.Parameters) == 0 {
 		return "this query has no parameter"
@@ -55,7 +207,11 @@ func (q *Query) GetParameterStringForUsage() string {
 
 	var v strings.Builder
 	for _, p := range q.Parameters {
-		v.WriteString(surroundString(p, "<", ">"))
+		if p.HasDefault {
+			v.WriteString(surroundString(p.UsageString()+"="+p.Default, "[", "]"))
+		} else {
+			v.WriteString(surroundString(p.UsageString(), "<", ">"))
+		}
 		v.WriteString(" ")
 	}
 	return v.String()
@@ -66,7 +222,19 @@ func LoadQueryFromFile(path string) (*Query, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file %s: %w", path, err)
 	}
+	return newQueryFromBytes(b)
+}
 
+// LoadQueryFromReader reads a query such as a query piped to stdin.
+func LoadQueryFromReader(r io.Reader) (*Query, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read query: %w", err)
+	}
+	return newQueryFromBytes(b)
+}
+
+func newQueryFromBytes(b []byte) (*Query, error) {
 	if bytes.HasPrefix(b, []byte("#!")) {
 		for i := 0; i < len(b); i++ {
 			if b[i] == '\n' {
@@ -77,18 +245,15 @@ func LoadQueryFromFile(path string) (*Query, error) {
 	return NewQuery(string(b))
 }
 
-func findParameter(s string) (string, int, error) {
-	open := strings.Index(s, "{{")
-	close := strings.Index(s, "}}")
-	if open == -1 {
-		return "", 0, ErrQueryOpenParamNotFound
-	}
-	if close == -1 {
-		return "", 0, ErrorQueryCloseParamNotFound
+// parameterName returns the parameter name of a placeholder key, which is
+// `name.start` or `name.end` for range parameters.
+func parameterName(key string) string {
+	for _, suffix := range []string{".start", ".end"} {
+		if base, ok := strings.CutSuffix(key, suffix); ok {
+			return base
+		}
 	}
-	ss := s[open+2 : close]
-	key := strings.TrimSpace(ss)
-	return key, close + 2, nil
+	return key
 }
 
 func surroundString(s, prefix, suffix string) string {
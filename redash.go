@@ -27,6 +27,33 @@ type RedashPostQueryResultRequest struct {
 	Query          string         `json:"query"`
 }
 
+type RedashPostQueryIDResultRequest struct {
+	ApplyAutoLimit bool           `json:"apply_auto_limit"`
+	MaxAge         int            `json:"max_age"`
+	Parameters     map[string]any `json:"parameters"`
+}
+
+type RedashQueryParameter struct {
+	Name        string `json:"name"`
+	Title       string `json:"title"`
+	Type        string `json:"type"`
+	Value       any    `json:"value"`
+	EnumOptions string `json:"enumOptions"`
+
+	MultiValuesOptions any `json:"multiValuesOptions"`
+}
+
+type RedashGetQueryResponse struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	Query        string `json:"query"`
+	DataSourceID int    `json:"data_source_id"`
+	Options      struct {
+		Parameters []RedashQueryParameter `json:"parameters"`
+	} `json:"options"`
+}
+
 type RedashGetJobResponse struct {
 	Job struct {
 		ID            string `json:"id"`
@@ -54,49 +81,61 @@ type RedashGetQueryResultResponse struct {
 	} `json:"query_result"`
 }
 
+// RedashPostQueryIDResultResponse holds either a job or, when redash answers
+// from its cache, the query result itself.
+type RedashPostQueryIDResultResponse struct {
+	RedashGetJobResponse
+	RedashGetQueryResultResponse
+}
+
+func (r *RedashPostQueryIDResultResponse) HasQueryResult() bool {
+	return r.QueryResult.ID != 0
+}
+
+func (r *RedashGetQueryResultResponse) GetResult() *Result {
+	return NewResult(r)
+}
+
+// GetTable returns the result formatted as strings with the header at first.
 func (r *RedashGetQueryResultResponse) GetTable() [][]string {
-	data := r.QueryResult.Data
-
-	table := make([][]string, len(data.Rows)+1)
-	tableHead := make([]string, len(data.Columns))
-	col2type := make(map[string]string, len(data.Rows))
-	for i, col := range data.Columns {
-		tableHead[i] = col.FriendlyName
-		col2type[col.Name] = col.Type
-	}
-
-	table[0] = tableHead
-	for i, row := range data.Rows {
-		tableRow := make([]string, len(data.Columns))
-		for j, column := range data.Columns {
-			v := row[column.Name]
-			if v == nil {
-				tableRow[j] = ""
-				continue
-			}
-			switch col2type[column.Name] {
-			case "integer":
-				tableRow[j] = fmt.Sprint(int(v.(float64)))
-			default:
-				tableRow[j] = fmt.Sprint(v)
-			}
-		}
-		table[i+1] = tableRow
-	}
-	return table
+	return r.GetResult().Table()
 }
 
 type RedashClient struct {
-	Endpoint string
-	APIKey   string
-	Logger   *slog.Logger
+	Endpoint    string
+	APIKey      string
+	Logger      *slog.Logger
+	PollPolicy  PollPolicy
+	RetryPolicy RetryPolicy
+	HTTPClient  *http.Client
+	Headers     http.Header
+	UserAgent   string
+	// OnProgress is called with the state of a job each time it is polled.
+	OnProgress func(JobProgress)
 }
 
-func NewRedashClient(endpoint, apiKey string, logger *slog.Logger) (*RedashClient, error) {
+func NewRedashClient(endpoint, apiKey string, logger *slog.Logger, opts ...ClientOption) (*RedashClient, error) {
+	o := &clientOptions{headers: http.Header{}, userAgent: "redac"}
+	if v := GetVersion(); v != "" {
+		o.userAgent += "/" + v
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	httpClient, err := o.buildHTTPClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create http client: %w", err)
+	}
+
 	rc := &RedashClient{}
 	rc.Endpoint = endpoint
 	rc.APIKey = apiKey
 	rc.Logger = logger
+	rc.PollPolicy = DefaultPollPolicy
+	rc.RetryPolicy = DefaultRetryPolicy
+	rc.HTTPClient = httpClient
+	rc.Headers = o.headers
+	rc.UserAgent = o.userAgent
 	return rc, nil
 }
 
@@ -105,9 +144,6 @@ func (rc *RedashClient) GetDataSources(ctx context.Context) ([]map[string]any, e
 	if err != nil {
 		return nil, fmt.Errorf("failed to get data sources. %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get data source, status=%d", resp.StatusCode)
-	}
 	var data []map[string]any
 	if err := rc.unmarshalResponse(resp, &data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response. %w", err)
@@ -121,31 +157,19 @@ func (rc *RedashClient) QueryAndWaitResult(ctx context.Context, req RedashPostQu
 	if err != nil {
 		return nil, err
 	}
-	jobID := job.Job.ID
-
-	for {
-		time.Sleep(time.Second)
-		job, err = rc.GetJob(ctx, jobID)
-
-		select {
-		case <-ctx.Done():
-			rc.cleanupJob(jobID)
-		default:
-		}
-
-		if err != nil {
-			return nil, err
-		}
-
-		if job.Job.Status == RedashJobStatusSuccess {
-			break
-		}
+	return rc.waitJobResult(ctx, job.Job.ID)
+}
 
-		if job.Job.Status == RedashJobStatusFailure {
-			return nil, fmt.Errorf("job is failed: %s", job.Job.Error)
-		}
+func (rc *RedashClient) QueryIDAndWaitResult(ctx context.Context, id int, req RedashPostQueryIDResultRequest) (*RedashGetQueryResultResponse, error) {
+	rc.Logger.Debug("QueryIDAndWaitResult", "id", id, "req", req)
+	data, err := rc.PostQueryIDResults(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	if data.HasQueryResult() {
+		return &data.RedashGetQueryResultResponse, nil
 	}
-	return rc.GetQueryResult(ctx, job.Job.QueryResultID)
+	return rc.waitJobResult(ctx, data.Job.ID)
 }
 
 func (rc *RedashClient) cleanupJob(jobID string) {
@@ -162,17 +186,41 @@ func (rc *RedashClient) PostQueryResults(ctx context.Context, req RedashPostQuer
 	if err != nil {
 		return nil, fmt.Errorf("failed to post query results. %w", err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("failed to post query result, status=%d, body=%s", resp.StatusCode, b)
-	}
 	var data RedashGetJobResponse
 	if err := rc.unmarshalResponse(resp, &data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response. %w", err)
 	}
 	if data.Job.Status == RedashJobStatusFailure {
-		return nil, fmt.Errorf("job failed: %s", data.Job.Error)
+		return nil, &JobError{JobID: data.Job.ID, Status: data.Job.Status, Message: data.Job.Error}
+	}
+	return &data, nil
+}
+
+func (rc *RedashClient) GetQuery(ctx context.Context, id int) (*RedashGetQueryResponse, error) {
+	api := fmt.Sprintf("queries/%d", id)
+	resp, err := rc.doRequest(ctx, http.MethodGet, api, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get query %d: %w", id, err)
+	}
+	var data RedashGetQueryResponse
+	if err := rc.unmarshalResponse(resp, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response. %w", err)
+	}
+	return &data, nil
+}
+
+func (rc *RedashClient) PostQueryIDResults(ctx context.Context, id int, req RedashPostQueryIDResultRequest) (*RedashPostQueryIDResultResponse, error) {
+	api := fmt.Sprintf("queries/%d/results", id)
+	resp, err := rc.doRequest(ctx, http.MethodPost, api, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to post query %d results. %w", id, err)
+	}
+	var data RedashPostQueryIDResultResponse
+	if err := rc.unmarshalResponseUseNumber(resp, &data); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response. %w", err)
+	}
+	if !data.HasQueryResult() && data.Job.Status == RedashJobStatusFailure {
+		return nil, &JobError{JobID: data.Job.ID, Status: data.Job.Status, Message: data.Job.Error}
 	}
 	return &data, nil
 }
@@ -181,12 +229,7 @@ func (rc *RedashClient) GetJob(ctx context.Context, id string) (*RedashGetJobRes
 	api := fmt.Sprintf("jobs/%s", id)
 	resp, err := rc.doRequest(ctx, http.MethodGet, api, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get job %s: %s", id, err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("failed to get job, status=%d, body=%s", resp.StatusCode, b)
+		return nil, fmt.Errorf("failed to get job %s: %w", id, err)
 	}
 	var data RedashGetJobResponse
 	if err := rc.unmarshalResponse(resp, &data); err != nil {
@@ -199,13 +242,9 @@ func (rc *RedashClient) DeleteJob(ctx context.Context, id string) error {
 	api := fmt.Sprintf("jobs/%s", id)
 	resp, err := rc.doRequest(ctx, http.MethodDelete, api, nil)
 	if err != nil {
-		return fmt.Errorf("failed to delete job %s: %s", id, err)
-	}
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return fmt.Errorf("failed to delete job, status=%d, body=%s", resp.StatusCode, b)
+		return fmt.Errorf("failed to delete job %s: %w", id, err)
 	}
+	resp.Body.Close()
 	return nil
 }
 
@@ -215,34 +254,66 @@ func (rc *RedashClient) GetQueryResult(ctx context.Context, id int) (*RedashGetQ
 	if err != nil {
 		return nil, fmt.Errorf("failed to get query result at request, id=%d: %w", id, err)
 	}
-	if resp.StatusCode != http.StatusOK {
-		b, _ := io.ReadAll(resp.Body)
-		resp.Body.Close()
-		return nil, fmt.Errorf("failed to get query result, status=%d, body=%s", resp.StatusCode, b)
-	}
 	var data RedashGetQueryResultResponse
-	if err := rc.unmarshalResponse(resp, &data); err != nil {
+	if err := rc.unmarshalResponseUseNumber(resp, &data); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal response. %w", err)
 	}
 	return &data, nil
 }
 
+// doRequest sends a request and returns the response when its status is 200,
+// otherwise an *APIError. Idempotent requests are retried by rc.RetryPolicy.
 func (rc *RedashClient) doRequest(ctx context.Context, method, api string, reqData any) (*http.Response, error) {
 	b, _ := json.Marshal(reqData)
-	br := bytes.NewBuffer(b)
-	req, err := rc.newRequest(method, api, br)
+	for attempt := 0; ; attempt++ {
+		resp, err := rc.sendRequest(ctx, method, api, b)
+		if err == nil {
+			return resp, nil
+		}
+		if !isIdempotentMethod(method) || attempt >= rc.RetryPolicy.MaxRetries || !isRetryableError(err) {
+			return nil, err
+		}
+		wait := rc.RetryPolicy.backoff(attempt, err)
+		rc.Logger.Warn("retry request", "method", method, "api", api, "wait", wait, "err", err)
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (rc *RedashClient) sendRequest(ctx context.Context, method, api string, body []byte) (*http.Response, error) {
+	req, err := rc.newRequest(method, api, bytes.NewReader(body))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request. %s %s, %w", req.Method, req.URL, err)
+		return nil, err
 	}
 	req = req.WithContext(ctx)
-	resp, err := http.DefaultClient.Do(req)
+	client := rc.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request. %s %s, %w", req.Method, req.URL, err)
 	}
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, newAPIError(resp, b)
+	}
 	return resp, nil
 }
 
 func (rc *RedashClient) unmarshalResponse(resp *http.Response, v any) error {
+	return rc.decodeResponse(resp, v, false)
+}
+
+// unmarshalResponseUseNumber keeps numbers as json.Number not to lose the
+// precision of the values in query results.
+func (rc *RedashClient) unmarshalResponseUseNumber(resp *http.Response, v any) error {
+	return rc.decodeResponse(resp, v, true)
+}
+
+func (rc *RedashClient) decodeResponse(resp *http.Response, v any, useNumber bool) error {
 	defer resp.Body.Close()
 
 	b, err := io.ReadAll(resp.Body)
@@ -250,18 +321,30 @@ func (rc *RedashClient) unmarshalResponse(resp *http.Response, v any) error {
 		return fmt.Errorf("failed to read response body: %w", err)
 	}
 	rc.Logger.Debug("unmarshal response", "body", string(b))
-	if err := json.Unmarshal(b, v); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if useNumber {
+		dec.UseNumber()
+	}
+	if err := dec.Decode(v); err != nil {
 		return fmt.Errorf("failed to unmarshal response body, err=%w, body=%s", err, string(b))
 	}
 	return nil
-
 }
+
 func (rc *RedashClient) newRequest(method, api string, body io.Reader) (*http.Request, error) {
 	url := fmt.Sprintf("%s/api/%s", rc.Endpoint, api)
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request. %s %s, %w", method, url, err)
 	}
+	for k, vs := range rc.Headers {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	if rc.UserAgent != "" {
+		req.Header.Set("User-Agent", rc.UserAgent)
+	}
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Key %s", rc.APIKey))
 	return req, nil
@@ -1,27 +1,53 @@
 package redac
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/csv"
 	"encoding/json"
+	"fmt"
+	"html"
 	"io"
+	"strings"
 
+	"github.com/mattn/go-runewidth"
 	"github.com/olekukonko/tablewriter"
 	"gopkg.in/yaml.v3"
 )
 
 type Renderer interface {
 	SetShowHeader(bool)
-	Render(io.Writer, [][]string) error
+	SetFormatter(*ValueFormatter)
+	Render(io.Writer, *Result) error
 }
 
 type rendererBase struct {
 	ShowHeader bool
+	Formatter  *ValueFormatter
 }
 
 func (r *rendererBase) SetShowHeader(showHeader bool) {
 	r.ShowHeader = showHeader
 }
 
+func (r *rendererBase) SetFormatter(f *ValueFormatter) {
+	r.Formatter = f
+}
+
+// table returns the header and the rows formatted by r.Formatter.
+func (r *rendererBase) table(result *Result) [][]string {
+	table := make([][]string, len(result.Rows)+1)
+	table[0] = result.Header()
+	for i, row := range result.Rows {
+		tableRow := make([]string, len(row))
+		for j, v := range row {
+			tableRow[j] = r.Formatter.Format(result.Columns[j], v)
+		}
+		table[i+1] = tableRow
+	}
+	return table
+}
+
 type tableType int
 
 const (
@@ -32,14 +58,41 @@ const (
 type TableRenderer struct {
 	rendererBase
 	TableType tableType
+	// MaxWidth truncates cells with an ellipsis so that the table fits in
+	// the width. Zero means no limit.
+	MaxWidth int
+	// Color colors the header and the values by their column types with
+	// ANSI escape sequences.
+	Color bool
 }
 
-func (r *TableRenderer) Render(w io.Writer, data [][]string) error {
+const minColumnWidth = 3
+
+var (
+	headerColor  = tablewriter.Colors{tablewriter.Bold}
+	nullColor    = tablewriter.Colors{tablewriter.FgHiBlackColor}
+	numberColor  = tablewriter.Colors{tablewriter.FgCyanColor}
+	booleanColor = tablewriter.Colors{tablewriter.FgYellowColor}
+	dateColor    = tablewriter.Colors{tablewriter.FgGreenColor}
+)
+
+func (r *TableRenderer) Render(w io.Writer, result *Result) error {
+	data := r.table(result)
+	if r.MaxWidth > 0 {
+		data = r.fitWidth(result, data)
+	}
+	r.render(w, result, data)
+	return nil
+}
+
+func (r *TableRenderer) render(w io.Writer, result *Result, data [][]string) {
 	table := tablewriter.NewWriter(w)
+	align := tablewriter.ALIGN_DEFAULT
 	switch r.TableType {
 	case TableType1:
 		table.SetBorder(false)
 	case TableType2:
+		align = tablewriter.ALIGN_LEFT
 		table.SetAutoWrapText(false)
 		table.SetAutoFormatHeaders(true)
 		table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
@@ -52,46 +105,353 @@ func (r *TableRenderer) Render(w io.Writer, data [][]string) error {
 		table.SetTablePadding("\t")
 		table.SetNoWhiteSpace(true)
 	}
+	if r.MaxWidth > 0 {
+		// cells are truncated instead of wrapped
+		table.SetAutoWrapText(false)
+	}
+
+	aligns := make([]int, len(result.Columns))
+	for i, col := range result.Columns {
+		aligns[i] = align
+		if col.IsNumeric() {
+			aligns[i] = tablewriter.ALIGN_RIGHT
+		}
+	}
+	table.SetColumnAlignment(aligns)
+
 	if r.ShowHeader {
 		table.SetHeader(data[0])
+		if r.Color {
+			colors := make([]tablewriter.Colors, len(data[0]))
+			for i := range colors {
+				colors[i] = headerColor
+			}
+			table.SetHeaderColor(colors...)
+		}
 	}
 
-	for _, v := range data[1:] {
-		table.Append(v)
+	for i, v := range data[1:] {
+		if !r.Color {
+			table.Append(v)
+			continue
+		}
+		colors := make([]tablewriter.Colors, len(v))
+		for j, value := range result.Rows[i] {
+			colors[j] = valueColor(result.Columns[j], value)
+		}
+		table.Rich(v, colors)
 	}
 	table.Render()
+}
+
+func valueColor(col Column, v Value) tablewriter.Colors {
+	switch {
+	case v.IsNull():
+		return nullColor
+	case col.IsNumeric():
+		return numberColor
+	case col.Type == ColumnTypeBoolean:
+		return booleanColor
+	case col.Type == ColumnTypeDatetime, col.Type == ColumnTypeDate:
+		return dateColor
+	}
 	return nil
 }
 
+// fitWidth shrinks the widest columns by the width the table overflows.
+func (r *TableRenderer) fitWidth(result *Result, data [][]string) [][]string {
+	var buf bytes.Buffer
+	r.render(&buf, result, data)
+	excess := maxLineWidth(buf.String()) - r.MaxWidth
+	if excess <= 0 {
+		return data
+	}
+
+	rows := data[1:]
+	if r.ShowHeader {
+		rows = data
+	}
+	widths := make([]int, len(data[0]))
+	for _, row := range rows {
+		for i, v := range row {
+			widths[i] = max(widths[i], maxLineWidth(v))
+		}
+	}
+	for ; excess > 0; excess-- {
+		widest := 0
+		for i, w := range widths {
+			if w > widths[widest] {
+				widest = i
+			}
+		}
+		if widths[widest] <= minColumnWidth {
+			break
+		}
+		widths[widest]--
+	}
+
+	fitted := make([][]string, len(data))
+	for i, row := range data {
+		fitted[i] = make([]string, len(row))
+		for j, v := range row {
+			lines := strings.Split(v, "\n")
+			for k, line := range lines {
+				lines[k] = runewidth.Truncate(line, widths[j], "…")
+			}
+			fitted[i][j] = strings.Join(lines, "\n")
+		}
+	}
+	return fitted
+}
+
 type CSVRenderer struct {
 	rendererBase
+	// Delimiter is the field delimiter, ',' if zero.
+	Delimiter rune
+	// QuoteAll quotes every field instead of only the fields which need it.
+	QuoteAll bool
+	CRLF     bool
 }
 
-func (r *CSVRenderer) Render(w io.Writer, data [][]string) error {
+func (r *CSVRenderer) Render(w io.Writer, result *Result) error {
+	data := r.table(result)
 	if !r.ShowHeader {
 		data = data[1:]
 	}
-	return csv.NewWriter(w).WriteAll(data)
+	if r.QuoteAll {
+		return r.writeQuoted(w, data)
+	}
+	cw := csv.NewWriter(w)
+	if r.Delimiter != 0 {
+		cw.Comma = r.Delimiter
+	}
+	cw.UseCRLF = r.CRLF
+	return cw.WriteAll(data)
+}
+
+// writeQuoted writes the records with all the fields quoted, which
+// encoding/csv does not support.
+func (r *CSVRenderer) writeQuoted(w io.Writer, data [][]string) error {
+	delimiter, newline := ",", "\n"
+	if r.Delimiter != 0 {
+		delimiter = string(r.Delimiter)
+	}
+	if r.CRLF {
+		newline = "\r\n"
+	}
+	bw := bufio.NewWriter(w)
+	for _, row := range data {
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteString(delimiter)
+			}
+			if r.CRLF {
+				v = strings.ReplaceAll(strings.ReplaceAll(v, "\r\n", "\n"), "\n", "\r\n")
+			}
+			bw.WriteString(`"`)
+			bw.WriteString(strings.ReplaceAll(v, `"`, `""`))
+			bw.WriteString(`"`)
+		}
+		bw.WriteString(newline)
+	}
+	return bw.Flush()
 }
 
+type jsonMode int
+
+const (
+	JSONModeArrays jsonMode = iota
+	JSONModeObjects
+	JSONModeLines
+)
+
 type JSONRenderer struct {
 	rendererBase
+	Mode jsonMode
 }
 
-func (r *JSONRenderer) Render(w io.Writer, data [][]string) error {
+func (r *JSONRenderer) Render(w io.Writer, result *Result) error {
+	if r.Mode == JSONModeArrays {
+		return json.NewEncoder(w).Encode(r.stringArrays(result))
+	}
+
+	bw := bufio.NewWriter(w)
+	if r.Mode == JSONModeObjects {
+		bw.WriteString("[")
+	}
+	for i, row := range result.Rows {
+		if i > 0 && r.Mode == JSONModeObjects {
+			bw.WriteString(",")
+		}
+		bw.WriteString("{")
+		for j, column := range result.Columns {
+			if j > 0 {
+				bw.WriteString(",")
+			}
+			k, _ := json.Marshal(column.Name)
+			v, err := json.Marshal(r.Formatter.Native(column, row[j]))
+			if err != nil {
+				return fmt.Errorf("failed to marshal value of %s: %w", column.Name, err)
+			}
+			bw.Write(k)
+			bw.WriteString(":")
+			bw.Write(v)
+		}
+		bw.WriteString("}")
+		if r.Mode == JSONModeLines {
+			bw.WriteString("\n")
+		}
+	}
+	if r.Mode == JSONModeObjects {
+		bw.WriteString("]\n")
+	}
+	return bw.Flush()
+}
+
+// stringArrays returns the formatted values as arrays of strings, which has been
+// the output of the arrays modes of json and yaml.
+func (r *rendererBase) stringArrays(result *Result) [][]string {
+	data := r.table(result)
 	if !r.ShowHeader {
 		data = data[1:]
 	}
-	return json.NewEncoder(w).Encode(data)
+	return data
 }
 
 type YAMLRenderer struct {
 	rendererBase
+	Objects bool
 }
 
-func (r *YAMLRenderer) Render(w io.Writer, data [][]string) error {
+func (r *YAMLRenderer) Render(w io.Writer, result *Result) error {
+	if !r.Objects {
+		return yaml.NewEncoder(w).Encode(r.stringArrays(result))
+	}
+
+	doc := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, row := range result.Rows {
+		m := &yaml.Node{Kind: yaml.MappingNode}
+		for i, column := range result.Columns {
+			v, ok := yamlValue(r.Formatter.Native(column, row[i])).(*yaml.Node)
+			if !ok {
+				v = &yaml.Node{}
+				if err := v.Encode(r.Formatter.Native(column, row[i])); err != nil {
+					return fmt.Errorf("failed to encode value of %s: %w", column.Name, err)
+				}
+			}
+			m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: column.Name}, v)
+		}
+		doc.Content = append(doc.Content, m)
+	}
+	return yaml.NewEncoder(w).Encode(doc)
+}
+
+// yamlValue converts json.Number, which yaml encodes as a string, to a
+// number node keeping the digits as they are.
+func yamlValue(v any) any {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	tag := "!!float"
+	if _, err := n.Int64(); err == nil {
+		tag = "!!int"
+	}
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: n.String()}
+}
+
+type MarkdownRenderer struct {
+	rendererBase
+}
+
+func (r *MarkdownRenderer) Render(w io.Writer, result *Result) error {
+	if len(result.Columns) == 0 {
+		return nil
+	}
+	data := r.table(result)
+	bw := bufio.NewWriter(w)
+	header := data[0]
 	if !r.ShowHeader {
-		data = data[1:]
+		// a table in GitHub flavored markdown always has a header row
+		header = make([]string, len(data[0]))
 	}
-	return yaml.NewEncoder(w).Encode(data)
+	writeMarkdownRow(bw, header)
+
+	bw.WriteString("|")
+	for _, col := range result.Columns {
+		if col.IsNumeric() {
+			bw.WriteString(" ---: |")
+		} else {
+			bw.WriteString(" :--- |")
+		}
+	}
+	bw.WriteString("\n")
+
+	for _, row := range data[1:] {
+		writeMarkdownRow(bw, row)
+	}
+	return bw.Flush()
+}
+
+var markdownCellReplacer = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>", "\r", "<br>")
+
+func writeMarkdownRow(w *bufio.Writer, row []string) {
+	w.WriteString("|")
+	for _, v := range row {
+		w.WriteString(" ")
+		w.WriteString(markdownCellReplacer.Replace(v))
+		w.WriteString(" |")
+	}
+	w.WriteString("\n")
+}
+
+type HTMLRenderer struct {
+	rendererBase
+	NoCSS bool
+}
+
+const htmlStyle = `table { border-collapse: collapse; font-family: sans-serif; font-size: 14px; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+th { background-color: #f0f0f0; text-align: left; }
+tr:nth-child(even) td { background-color: #fafafa; }
+td.number { text-align: right; }
+td.null { color: #999; }
+`
+
+func (r *HTMLRenderer) Render(w io.Writer, result *Result) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	if !r.NoCSS {
+		bw.WriteString("<style>\n" + htmlStyle + "</style>\n")
+	}
+	bw.WriteString("</head>\n<body>\n<table>\n")
+
+	if r.ShowHeader {
+		bw.WriteString("<thead>\n<tr>")
+		for _, v := range result.Header() {
+			bw.WriteString("<th>" + html.EscapeString(v) + "</th>")
+		}
+		bw.WriteString("</tr>\n</thead>\n")
+	}
+	bw.WriteString("<tbody>\n")
+	for _, row := range result.Rows {
+		bw.WriteString("<tr>")
+		for i, v := range row {
+			switch {
+			case v.IsNull():
+				bw.WriteString(`<td class="null">`)
+			case result.Columns[i].IsNumeric():
+				bw.WriteString(`<td class="number">`)
+			default:
+				bw.WriteString("<td>")
+			}
+			bw.WriteString(strings.ReplaceAll(html.EscapeString(r.Formatter.Format(result.Columns[i], v)), "\n", "<br>"))
+			bw.WriteString("</td>")
+		}
+		bw.WriteString("</tr>\n")
+	}
+	bw.WriteString("</tbody>\n")
+
+	bw.WriteString("</table>\n</body>\n</html>\n")
+	return bw.Flush()
 }
@@ -0,0 +1,205 @@
+package redac
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+type ColumnType string
+
+const (
+	ColumnTypeInteger  ColumnType = "integer"
+	ColumnTypeFloat    ColumnType = "float"
+	ColumnTypeBoolean  ColumnType = "boolean"
+	ColumnTypeString   ColumnType = "string"
+	ColumnTypeDatetime ColumnType = "datetime"
+	ColumnTypeDate     ColumnType = "date"
+)
+
+var datetimeValueLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02 15:04:05.999999999", "2006-01-02"}
+
+type Column struct {
+	Name         string
+	FriendlyName string
+	Type         ColumnType
+}
+
+func (c Column) IsNumeric() bool {
+	return c.Type == ColumnTypeInteger || c.Type == ColumnTypeFloat
+}
+
+// Value is a value of a query result. V is nil for NULL, otherwise int64,
+// float64, bool, string or time.Time according to the type of the column.
+// Raw is the value as decoded from the response of redash.
+type Value struct {
+	V   any
+	Raw any
+}
+
+func (v Value) IsNull() bool {
+	return v.V == nil
+}
+
+func (v Value) String() string {
+	switch x := v.V.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case string:
+		return x
+	case time.Time:
+		if s, ok := v.Raw.(string); ok {
+			return s
+		}
+		return x.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v.V)
+}
+
+// Result is a query result with typed values.
+type Result struct {
+	Columns []Column
+	Rows    [][]Value
+}
+
+func NewResult(resp *RedashGetQueryResultResponse) *Result {
+	data := resp.QueryResult.Data
+	r := &Result{
+		Columns: make([]Column, len(data.Columns)),
+		Rows:    make([][]Value, len(data.Rows)),
+	}
+	for i, col := range data.Columns {
+		r.Columns[i] = Column{Name: col.Name, FriendlyName: col.FriendlyName, Type: ColumnType(col.Type)}
+		if r.Columns[i].FriendlyName == "" {
+			r.Columns[i].FriendlyName = col.Name
+		}
+	}
+	for i, row := range data.Rows {
+		values := make([]Value, len(r.Columns))
+		for j, col := range r.Columns {
+			values[j] = newValue(col.Type, row[col.Name])
+		}
+		r.Rows[i] = values
+	}
+	return r
+}
+
+// Header returns the friendly names of the columns.
+func (r *Result) Header() []string {
+	header := make([]string, len(r.Columns))
+	for i, col := range r.Columns {
+		header[i] = col.FriendlyName
+	}
+	return header
+}
+
+// Table returns the header and the rows formatted as strings.
+func (r *Result) Table() [][]string {
+	table := make([][]string, len(r.Rows)+1)
+	table[0] = r.Header()
+	for i, row := range r.Rows {
+		tableRow := make([]string, len(row))
+		for j, v := range row {
+			tableRow[j] = v.String()
+		}
+		table[i+1] = tableRow
+	}
+	return table
+}
+
+// newValue converts a decoded JSON value to the type of the column. A value
+// which cannot be converted is kept as it is.
+func newValue(t ColumnType, raw any) Value {
+	v := Value{Raw: raw}
+	if raw == nil {
+		return v
+	}
+	switch t {
+	case ColumnTypeInteger:
+		switch x := raw.(type) {
+		case json.Number:
+			if i, err := x.Int64(); err == nil {
+				v.V = i
+				return v
+			}
+			if f, err := x.Float64(); err == nil {
+				v.V = f
+				return v
+			}
+		case float64:
+			if x == float64(int64(x)) {
+				v.V = int64(x)
+			} else {
+				v.V = x
+			}
+			return v
+		case string:
+			if i, err := strconv.ParseInt(x, 10, 64); err == nil {
+				v.V = i
+				return v
+			}
+		}
+	case ColumnTypeFloat:
+		switch x := raw.(type) {
+		case json.Number:
+			if f, err := x.Float64(); err == nil {
+				v.V = f
+				return v
+			}
+		case float64:
+			v.V = x
+			return v
+		case string:
+			if f, err := strconv.ParseFloat(x, 64); err == nil {
+				v.V = f
+				return v
+			}
+		}
+	case ColumnTypeBoolean:
+		switch x := raw.(type) {
+		case bool:
+			v.V = x
+			return v
+		case string:
+			if b, err := strconv.ParseBool(x); err == nil {
+				v.V = b
+				return v
+			}
+		}
+	case ColumnTypeDatetime, ColumnTypeDate:
+		if s, ok := raw.(string); ok {
+			for _, layout := range datetimeValueLayouts {
+				if tm, err := time.Parse(layout, s); err == nil {
+					v.V = tm
+					return v
+				}
+			}
+		}
+	}
+
+	switch x := raw.(type) {
+	case string:
+		v.V = x
+	case bool:
+		v.V = x
+	case json.Number:
+		v.V = x.String()
+	case float64:
+		v.V = strconv.FormatFloat(x, 'f', -1, 64)
+	default:
+		b, err := json.Marshal(x)
+		if err != nil {
+			v.V = fmt.Sprint(x)
+		} else {
+			v.V = string(b)
+		}
+	}
+	return v
+}
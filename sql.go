@@ -0,0 +1,109 @@
+package redac
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+type sqlMode int
+
+const (
+	SQLModeInsert sqlMode = iota
+	SQLModeCopy
+)
+
+const defaultSQLTableName = "result"
+
+// SQLRenderer renders a result as INSERT statements or a PostgreSQL COPY block.
+type SQLRenderer struct {
+	rendererBase
+	Mode      sqlMode
+	TableName string
+	// BatchSize is the number of rows in an INSERT statement.
+	BatchSize int
+}
+
+func (r *SQLRenderer) Render(w io.Writer, result *Result) error {
+	bw := bufio.NewWriter(w)
+	columns := make([]string, len(result.Columns))
+	for i, col := range result.Columns {
+		columns[i] = quoteSQLIdentifier(col.Name)
+	}
+	target := r.quotedTableName() + " (" + strings.Join(columns, ", ") + ")"
+
+	if r.Mode == SQLModeCopy {
+		bw.WriteString("COPY " + target + " FROM stdin;\n")
+		for _, row := range result.Rows {
+			for i, v := range row {
+				if i > 0 {
+					bw.WriteString("\t")
+				}
+				bw.WriteString(copyValue(v))
+			}
+			bw.WriteString("\n")
+		}
+		bw.WriteString("\\.\n")
+		return bw.Flush()
+	}
+
+	batchSize := max(r.BatchSize, 1)
+	for i, row := range result.Rows {
+		if i%batchSize == 0 {
+			bw.WriteString("INSERT INTO " + target + " VALUES\n  (")
+		} else {
+			bw.WriteString(",\n  (")
+		}
+		for j, v := range row {
+			if j > 0 {
+				bw.WriteString(", ")
+			}
+			bw.WriteString(sqlLiteral(v))
+		}
+		bw.WriteString(")")
+		if i%batchSize == batchSize-1 || i == len(result.Rows)-1 {
+			bw.WriteString(";\n")
+		}
+	}
+	return bw.Flush()
+}
+
+func (r *SQLRenderer) quotedTableName() string {
+	name := r.TableName
+	if name == "" {
+		name = defaultSQLTableName
+	}
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = quoteSQLIdentifier(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+func quoteSQLIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func sqlLiteral(v Value) string {
+	switch x := v.V.(type) {
+	case nil:
+		return "NULL"
+	case int64, float64:
+		return v.String()
+	case bool:
+		if x {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return "'" + strings.ReplaceAll(v.String(), "'", "''") + "'"
+}
+
+var copyValueReplacer = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+func copyValue(v Value) string {
+	if v.IsNull() {
+		return `\N`
+	}
+	return copyValueReplacer.Replace(v.String())
+}
@@ -0,0 +1,52 @@
+package redac
+
+import "testing"
+
+func TestSQLLiteral(t *testing.T) {
+	tests := []struct {
+		name  string
+		value Value
+		want  string
+	}{
+		{name: "null", value: Value{}, want: "NULL"},
+		{name: "int", value: Value{V: int64(-42)}, want: "-42"},
+		{name: "float", value: Value{V: 1.5}, want: "1.5"},
+		{name: "true", value: Value{V: true}, want: "TRUE"},
+		{name: "false", value: Value{V: false}, want: "FALSE"},
+		{name: "string", value: Value{V: "abc"}, want: "'abc'"},
+		{name: "quote", value: Value{V: "it's"}, want: "'it''s'"},
+		{name: "backslash", value: Value{V: `a\b`}, want: `'a\b'`},
+		{name: "tab and newline", value: Value{V: "a\tb\nc"}, want: "'a\tb\nc'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sqlLiteral(tt.value); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCopyValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value Value
+		want  string
+	}{
+		{name: "null", value: Value{}, want: `\N`},
+		{name: "empty string", value: Value{V: ""}, want: ""},
+		{name: "int", value: Value{V: int64(-42)}, want: "-42"},
+		{name: "quote", value: Value{V: `it's "x"`}, want: `it's "x"`},
+		{name: "backslash", value: Value{V: `a\b`}, want: `a\\b`},
+		{name: "literal backslash n", value: Value{V: `a\nb`}, want: `a\\nb`},
+		{name: "tab", value: Value{V: "a\tb"}, want: `a\tb`},
+		{name: "newlines", value: Value{V: "a\r\nb"}, want: `a\r\nb`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := copyValue(tt.value); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package redac
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// TemplateRenderer renders a result with a text/template. The template is
+// executed with TemplateData.
+type TemplateRenderer struct {
+	rendererBase
+	Template *template.Template
+}
+
+// TemplateData is the data passed to the template of TemplateRenderer.
+type TemplateData struct {
+	Columns []Column
+	// Header is the friendly names of the columns.
+	Header []string
+	// Rows is the values formatted as strings.
+	Rows [][]string
+	// Records is the formatted values of each row keyed by the column names.
+	Records []map[string]string
+	// Values is the values keeping their types as in json output.
+	Values     [][]any
+	ShowHeader bool
+}
+
+func NewTemplateRenderer(name, text string) (*TemplateRenderer, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return &TemplateRenderer{Template: t}, nil
+}
+
+var templateFuncs = template.FuncMap{
+	"quote":      strconv.Quote,
+	"shellquote": shellQuote,
+	"sqlquote":   sqlQuote,
+	"join":       templateJoin,
+	"format":     templateFormat,
+	"json":       templateJSON,
+	"upper":      strings.ToUpper,
+	"lower":      strings.ToLower,
+	"replace":    strings.ReplaceAll,
+	"trim":       strings.TrimSpace,
+}
+
+func (r *TemplateRenderer) Render(w io.Writer, result *Result) error {
+	data := TemplateData{
+		Columns:    result.Columns,
+		Header:     result.Header(),
+		Rows:       r.table(result)[1:],
+		Records:    make([]map[string]string, len(result.Rows)),
+		Values:     make([][]any, len(result.Rows)),
+		ShowHeader: r.ShowHeader,
+	}
+	for i, row := range data.Rows {
+		data.Records[i] = make(map[string]string, len(row))
+		data.Values[i] = make([]any, len(row))
+		for j, v := range row {
+			data.Records[i][result.Columns[j].Name] = v
+			data.Values[i][j] = r.Formatter.Native(result.Columns[j], result.Rows[i][j])
+		}
+	}
+	if err := r.Template.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to execute template: %w", err)
+	}
+	return nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func sqlQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// templateJoin joins the elements of a slice such as a row or the header.
+func templateJoin(sep string, list any) (string, error) {
+	switch x := list.(type) {
+	case []string:
+		return strings.Join(x, sep), nil
+	case []any:
+		s := make([]string, len(x))
+		for i, v := range x {
+			if v != nil {
+				s[i] = fmt.Sprint(v)
+			}
+		}
+		return strings.Join(s, sep), nil
+	}
+	return "", fmt.Errorf("join: unsupported type %T", list)
+}
+
+// templateFormat is fmt.Sprintf which also accepts numbers of Values for
+// numeric verbs.
+func templateFormat(format string, args ...any) string {
+	for i, a := range args {
+		n, ok := a.(json.Number)
+		if !ok {
+			continue
+		}
+		if v, err := n.Int64(); err == nil {
+			args[i] = v
+		} else if v, err := n.Float64(); err == nil {
+			args[i] = v
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func templateJSON(v any) (string, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
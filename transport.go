@@ -0,0 +1,118 @@
+package redac
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+type clientOptions struct {
+	httpClient         *http.Client
+	caBundle           string
+	clientCert         string
+	clientKey          string
+	insecureSkipVerify bool
+	proxy              string
+	headers            http.Header
+	userAgent          string
+}
+
+type ClientOption func(*clientOptions)
+
+func WithHTTPClient(c *http.Client) ClientOption {
+	return func(o *clientOptions) { o.httpClient = c }
+}
+
+// WithCABundle trusts the certificates in the PEM file in addition to the system ones.
+func WithCABundle(path string) ClientOption {
+	return func(o *clientOptions) { o.caBundle = path }
+}
+
+func WithClientCertificate(certFile, keyFile string) ClientOption {
+	return func(o *clientOptions) { o.clientCert, o.clientKey = certFile, keyFile }
+}
+
+func WithInsecureSkipVerify(skip bool) ClientOption {
+	return func(o *clientOptions) { o.insecureSkipVerify = skip }
+}
+
+func WithProxy(proxyURL string) ClientOption {
+	return func(o *clientOptions) { o.proxy = proxyURL }
+}
+
+func WithHeader(key, value string) ClientOption {
+	return func(o *clientOptions) { o.headers.Add(key, value) }
+}
+
+func WithUserAgent(ua string) ClientOption {
+	return func(o *clientOptions) { o.userAgent = ua }
+}
+
+func (o *clientOptions) needsTransport() bool {
+	return o.caBundle != "" || o.clientCert != "" || o.insecureSkipVerify || o.proxy != ""
+}
+
+func (o *clientOptions) buildHTTPClient() (*http.Client, error) {
+	client := o.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if !o.needsTransport() {
+		return client, nil
+	}
+
+	var transport *http.Transport
+	switch t := client.Transport.(type) {
+	case nil:
+		transport = http.DefaultTransport.(*http.Transport).Clone()
+	case *http.Transport:
+		transport = t.Clone()
+	default:
+		return nil, fmt.Errorf("tls and proxy options cannot be applied to transport %T", t)
+	}
+
+	tlsConfig := transport.TLSClientConfig
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	if o.caBundle != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		b, err := os.ReadFile(o.caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca bundle %s: %w", o.caBundle, err)
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, fmt.Errorf("no certificate found in ca bundle %s", o.caBundle)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if o.clientCert != "" || o.clientKey != "" {
+		cert, err := tls.LoadX509KeyPair(o.clientCert, o.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = append(tlsConfig.Certificates, cert)
+	}
+	if o.insecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	if o.proxy != "" {
+		u, err := url.Parse(o.proxy)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse proxy url: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+
+	c := *client
+	c.Transport = transport
+	return &c, nil
+}
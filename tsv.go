@@ -0,0 +1,66 @@
+package redac
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// TSVRenderer renders a result as tab separated values. Backslashes, tabs
+// and newlines in values are escaped as in the text format of COPY.
+type TSVRenderer struct {
+	rendererBase
+}
+
+func (r *TSVRenderer) Render(w io.Writer, result *Result) error {
+	data := r.table(result)
+	if !r.ShowHeader {
+		data = data[1:]
+	}
+	bw := bufio.NewWriter(w)
+	for _, row := range data {
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteString("\t")
+			}
+			bw.WriteString(copyValueReplacer.Replace(v))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// LTSVRenderer renders each row as a line of `label:value` fields separated
+// by tabs (http://ltsv.org/). The labels are the column names and values are
+// escaped as in TSVRenderer.
+type LTSVRenderer struct {
+	rendererBase
+}
+
+func (r *LTSVRenderer) Render(w io.Writer, result *Result) error {
+	data := r.table(result)
+	bw := bufio.NewWriter(w)
+	for _, row := range data[1:] {
+		for i, v := range row {
+			if i > 0 {
+				bw.WriteString("\t")
+			}
+			bw.WriteString(ltsvLabel(result.Columns[i].Name))
+			bw.WriteString(":")
+			bw.WriteString(copyValueReplacer.Replace(v))
+		}
+		bw.WriteString("\n")
+	}
+	return bw.Flush()
+}
+
+// ltsvLabel replaces the characters not allowed in LTSV labels with `_`.
+func ltsvLabel(name string) string {
+	return strings.Map(func(c rune) rune {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '.', c == '-':
+			return c
+		}
+		return '_'
+	}, name)
+}
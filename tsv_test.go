@@ -0,0 +1,41 @@
+package redac
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTSVRenderer(t *testing.T) {
+	tests := []struct {
+		name  string
+		value Value
+		null  string
+		want  string
+	}{
+		{name: "string", value: Value{V: "abc"}, want: "abc\n"},
+		{name: "quotes", value: Value{V: `it's "x"`}, want: "it's \"x\"\n"},
+		{name: "backslash", value: Value{V: `a\b`}, want: "a\\\\b\n"},
+		{name: "tab", value: Value{V: "a\tb"}, want: "a\\tb\n"},
+		{name: "newlines", value: Value{V: "a\r\nb"}, want: "a\\r\\nb\n"},
+		{name: "null", value: Value{}, want: "\n"},
+		{name: "null string", value: Value{}, null: "NULL", want: "NULL\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewValueFormatter()
+			f.Null = tt.null
+			r := &TSVRenderer{rendererBase{Formatter: f}}
+			result := &Result{
+				Columns: []Column{{Name: "v", FriendlyName: "v", Type: ColumnTypeString}},
+				Rows:    [][]Value{{tt.value}},
+			}
+			var b bytes.Buffer
+			if err := r.Render(&b, result); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package redac
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// VerticalRenderer renders each row as a block of `column | value` lines
+// like the expanded display of psql.
+type VerticalRenderer struct {
+	rendererBase
+}
+
+func (r *VerticalRenderer) Render(w io.Writer, result *Result) error {
+	data := r.table(result)
+	header := data[0]
+	keyWidth, valueWidth := 0, 0
+	for _, h := range header {
+		keyWidth = max(keyWidth, runewidth.StringWidth(h))
+	}
+	for _, row := range data[1:] {
+		for _, v := range row {
+			for _, line := range strings.Split(v, "\n") {
+				valueWidth = max(valueWidth, runewidth.StringWidth(line))
+			}
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	for i, row := range data[1:] {
+		title := fmt.Sprintf("-[ RECORD %d ]", i+1)
+		bw.WriteString(title)
+		bw.WriteString(strings.Repeat("-", max(keyWidth+1-len(title), 0)))
+		bw.WriteString("+")
+		bw.WriteString(strings.Repeat("-", valueWidth+1))
+		bw.WriteString("\n")
+		for j, v := range row {
+			for k, line := range strings.Split(v, "\n") {
+				key := ""
+				if k == 0 {
+					key = header[j]
+				}
+				bw.WriteString(runewidth.FillRight(key, keyWidth))
+				bw.WriteString(" | ")
+				bw.WriteString(line)
+				bw.WriteString("\n")
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// AutoRenderer renders a result as a table, or vertically when the table is
+// wider than Width.
+type AutoRenderer struct {
+	rendererBase
+	Width int
+	Color bool
+}
+
+func (r *AutoRenderer) Render(w io.Writer, result *Result) error {
+	table := &TableRenderer{TableType: TableType1, rendererBase: r.rendererBase, Color: r.Color}
+	var buf bytes.Buffer
+	if err := table.Render(&buf, result); err != nil {
+		return err
+	}
+	if r.Width <= 0 || maxLineWidth(buf.String()) <= r.Width {
+		_, err := buf.WriteTo(w)
+		return err
+	}
+	vertical := &VerticalRenderer{rendererBase: r.rendererBase}
+	return vertical.Render(w, result)
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func maxLineWidth(s string) int {
+	width := 0
+	for _, line := range strings.Split(ansiEscape.ReplaceAllString(s, ""), "\n") {
+		width = max(width, runewidth.StringWidth(line))
+	}
+	return width
+}
@@ -0,0 +1,142 @@
+package redac
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	xlsxSheetName      = "Sheet1"
+	xlsxMaxColumnWidth = 80
+	xlsxDatetimeFormat = "yyyy-mm-dd hh:mm:ss"
+	xlsxDateFormat     = "yyyy-mm-dd"
+	// xlsxMaxInt is the largest integer excel keeps exactly with its 15
+	// significant digits. Larger ones are written as text.
+	xlsxMaxInt = 999_999_999_999_999
+)
+
+// XLSXRenderer renders a result as an Excel workbook with typed cells.
+type XLSXRenderer struct {
+	rendererBase
+}
+
+func (r *XLSXRenderer) Render(w io.Writer, result *Result) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	sw, err := f.NewStreamWriter(xlsxSheetName)
+	if err != nil {
+		return fmt.Errorf("failed to create stream writer: %w", err)
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	datetimeStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: ptr(xlsxDatetimeFormat)})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: ptr(xlsxDateFormat)})
+	if err != nil {
+		return fmt.Errorf("failed to create style: %w", err)
+	}
+
+	// widths have to be set before any row is written
+	table := r.table(result)
+	for i, col := range result.Columns {
+		width := 0
+		for j, row := range table {
+			if j == 0 && !r.ShowHeader {
+				continue
+			}
+			width = max(width, runewidth.StringWidth(row[i]))
+		}
+		if col.Type == ColumnTypeDatetime {
+			width = max(width, len(xlsxDatetimeFormat))
+		}
+		if err := sw.SetColWidth(i+1, i+1, float64(min(width+2, xlsxMaxColumnWidth))); err != nil {
+			return fmt.Errorf("failed to set column width: %w", err)
+		}
+	}
+
+	rowNum := 1
+	if r.ShowHeader {
+		if err := sw.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return fmt.Errorf("failed to freeze header: %w", err)
+		}
+		cells := make([]any, len(result.Columns))
+		for i, h := range result.Header() {
+			cells[i] = excelize.Cell{StyleID: headerStyle, Value: h}
+		}
+		if err := r.setRow(sw, rowNum, cells); err != nil {
+			return err
+		}
+		rowNum++
+	}
+
+	for _, row := range result.Rows {
+		cells := make([]any, len(row))
+		for i, v := range row {
+			col := result.Columns[i]
+			switch x := v.V.(type) {
+			case nil:
+				if r.Formatter != nil && r.Formatter.Null != "" {
+					cells[i] = r.Formatter.Null
+				}
+			case int64:
+				if x > xlsxMaxInt || x < -xlsxMaxInt {
+					cells[i] = strconv.FormatInt(x, 10)
+				} else {
+					cells[i] = x
+				}
+			case float64, bool:
+				cells[i] = x
+			case time.Time:
+				if r.Formatter != nil && r.Formatter.Location != nil && col.Type == ColumnTypeDatetime {
+					x = x.In(r.Formatter.Location)
+				}
+				// excel has no time zone, so the wall clock is written
+				x = time.Date(x.Year(), x.Month(), x.Day(), x.Hour(), x.Minute(), x.Second(), x.Nanosecond(), time.UTC)
+				style := datetimeStyle
+				if col.Type == ColumnTypeDate {
+					style = dateStyle
+				}
+				cells[i] = excelize.Cell{StyleID: style, Value: x}
+			default:
+				cells[i] = r.Formatter.Format(col, v)
+			}
+		}
+		if err := r.setRow(sw, rowNum, cells); err != nil {
+			return err
+		}
+		rowNum++
+	}
+
+	if err := sw.Flush(); err != nil {
+		return fmt.Errorf("failed to flush sheet: %w", err)
+	}
+	if err := f.Write(w); err != nil {
+		return fmt.Errorf("failed to write xlsx: %w", err)
+	}
+	return nil
+}
+
+func (r *XLSXRenderer) setRow(sw *excelize.StreamWriter, rowNum int, cells []any) error {
+	cell, err := excelize.CoordinatesToCellName(1, rowNum)
+	if err != nil {
+		return fmt.Errorf("failed to get cell name: %w", err)
+	}
+	if err := sw.SetRow(cell, cells); err != nil {
+		return fmt.Errorf("failed to write row %d: %w", rowNum, err)
+	}
+	return nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}